@@ -0,0 +1,396 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+// punchConfirmTTL é a validade do token de confirmação de POST /punch.
+const punchConfirmTTL = time.Minute
+
+// apiEvent é uma mensagem enviada aos clientes de GET /events.
+type apiEvent struct {
+	name string
+	data any
+}
+
+// alertEvent descreve um alerta de intervalo emitido pelo daemon.
+type alertEvent struct {
+	Message        string `json:"message"`
+	Urgency        string `json:"urgency"`
+	ElapsedSeconds int64  `json:"elapsedSeconds"`
+}
+
+// punchConfirmation é a resposta a um POST /punch sem confirmação válida.
+type punchConfirmation struct {
+	Message      string `json:"message"`
+	ConfirmToken string `json:"confirmToken"`
+	ExpiresAt    string `json:"expiresAt"`
+	// Intent é o significado da marcação no dia (ver core.PunchIntent) e
+	// Details, as consequências dela.
+	Intent  string   `json:"intent"`
+	Details []string `json:"details,omitempty"`
+	// Blocked lista os motivos que exigem ?force=true na confirmação (ver
+	// core.CheckPunch).
+	Blocked []string `json:"blocked,omitempty"`
+}
+
+type punchResult struct {
+	DateEvent string       `json:"dateEvent"`
+	TimeEvent string       `json:"timeEvent"`
+	Status    statusReport `json:"status"`
+}
+
+type apiError struct {
+	Error   string   `json:"error"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// eventBroker distribui eventos para os clientes SSE conectados. Clientes
+// lentos perdem eventos em vez de travar o daemon.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan apiEvent]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{subscribers: make(map[chan apiEvent]struct{})}
+}
+
+func (b *eventBroker) subscribe() chan apiEvent {
+	ch := make(chan apiEvent, 8)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan apiEvent) {
+	b.mu.Lock()
+	delete(b.subscribers, ch)
+	b.mu.Unlock()
+}
+
+// publish é seguro com broker nil (daemon sem API HTTP).
+func (b *eventBroker) publish(name string, data any) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- apiEvent{name: name, data: data}:
+		default:
+		}
+	}
+}
+
+// apiServer expõe o estado do daemon por HTTP em localhost.
+type apiServer struct {
+	daemon        *daemon
+	broker        *eventBroker
+	allowedOrigin string
+	// port é a porta em que a API escuta, exigida no cabeçalho Host.
+	port string
+
+	mu            sync.Mutex
+	confirmTokens map[string]time.Time
+}
+
+// validateLoopbackAddr garante que a API só escute em interfaces locais: ela
+// expõe dados pessoais e permite bater o ponto.
+func validateLoopbackAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("endereço HTTP inválido %q: %v", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("a API HTTP só pode escutar em localhost (recebido %q)", addr)
+}
+
+// newAPIServer cria a API para o daemon d escutando em addr (já validado por
+// validateLoopbackAddr).
+func newAPIServer(d *daemon, addr, allowedOrigin string) *apiServer {
+	_, port, _ := net.SplitHostPort(addr)
+	return &apiServer{
+		daemon:        d,
+		broker:        d.broker,
+		allowedOrigin: allowedOrigin,
+		port:          port,
+		confirmTokens: make(map[string]time.Time),
+	}
+}
+
+func (a *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", a.handleStatus)
+	mux.HandleFunc("GET /history", a.handleHistory)
+	mux.HandleFunc("POST /punch", a.handlePunch)
+	mux.HandleFunc("GET /events", a.handleEvents)
+	mux.HandleFunc("GET /metrics", a.handleMetrics)
+	return a.withLocalOnly(a.withCORS(mux))
+}
+
+// withLocalOnly barra o DNS rebinding: uma página hostil que aponte o próprio
+// domínio para 127.0.0.1 fala com a API como mesma origem, então só escutar no
+// loopback não basta. Exige um Host local na porta da API e, nas rotas que
+// alteram estado, recusa origens diferentes da liberada (clientes fora do
+// navegador não enviam Origin).
+func (a *apiServer) withLocalOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.localHost(r.Host) {
+			writeJSON(w, http.StatusForbidden, apiError{Error: fmt.Sprintf("host não permitido: %q", r.Host)})
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if origin := r.Header.Get("Origin"); origin != "" && origin != a.allowedOrigin {
+				writeJSON(w, http.StatusForbidden, apiError{Error: fmt.Sprintf("origem não permitida: %q", origin)})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// localHost aceita apenas 127.0.0.1, localhost e [::1] na porta da API.
+func (a *apiServer) localHost(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil || port != a.port {
+		return false
+	}
+	switch strings.ToLower(host) {
+	case "127.0.0.1", "localhost", "::1":
+		return true
+	}
+	return false
+}
+
+// withCORS libera apenas a origem configurada explicitamente; sem ela, o
+// navegador bloqueia a leitura das respostas por outras páginas.
+func (a *apiServer) withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.allowedOrigin != "" && r.Header.Get("Origin") == a.allowedOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", a.allowedOrigin)
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// currentMsg devolve os últimos eventos do daemon, buscando-os se ainda não
+// houver nenhum.
+func (a *apiServer) currentMsg() (eventMsg, error) {
+	d := a.daemon
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.fetchedAt.IsZero() {
+		if err := d.refresh(); err != nil {
+			return eventMsg{}, err
+		}
+	}
+	return d.msg, nil
+}
+
+func (a *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	msg, err := a.currentMsg()
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, apiError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, buildStatusReport(msg, core.Now()))
+}
+
+func (a *apiServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	for _, key := range []string{from, to} {
+		if _, ok := parseDateKey(key); key != "" && !ok {
+			writeJSON(w, http.StatusBadRequest, apiError{
+				Error: fmt.Sprintf("data inválida %q (use AAAA-MM-DD)", key),
+			})
+			return
+		}
+	}
+
+	msg, err := a.currentMsg()
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, apiError{Error: err.Error()})
+		return
+	}
+
+	// Períodos anteriores aos dados do daemon são buscados na Senior.
+	if earliest := earliestDateKey(msg.clocking); from != "" && (earliest == "" || from < earliest) {
+		resp := a.daemon.dispatch(core.IPCRequest{Method: core.IPCMethodRange, From: from, To: to})
+		if resp.Error != "" {
+			writeJSON(w, http.StatusBadGateway, apiError{Error: resp.Error})
+			return
+		}
+		clocking, err := groupClockingEvents(resp.Events)
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, apiError{Error: err.Error()})
+			return
+		}
+		msg = withClocking(msg, clocking)
+	}
+
+	writeJSON(w, http.StatusOK, buildRangeReport(msg, loadAudits(), from, to, core.Now()))
+}
+
+func (a *apiServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	d := a.daemon
+	d.mu.Lock()
+	msg, fetchedAt := d.msg, d.fetchedAt
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, msg, fetchedAt, core.Now())
+}
+
+// handlePunch exige duas chamadas: a primeira devolve 428 com um token de uso
+// único; a segunda, com ?confirm=<token> dentro de punchConfirmTTL, registra
+// a marcação. Uma marcação bloqueada (intervalo mínimo, limite do expediente)
+// recebe 409 e exige também ?force=true. A marcação nunca é repetida
+// automaticamente.
+func (a *apiServer) handlePunch(w http.ResponseWriter, r *http.Request) {
+	a.daemon.mu.Lock()
+	summary := summarizePunch(a.daemon.msg, core.Now())
+	a.daemon.mu.Unlock()
+
+	token := r.URL.Query().Get("confirm")
+	if token != "" && len(summary.BlockReasons) > 0 && r.URL.Query().Get("force") != "true" {
+		writeJSON(w, http.StatusConflict, apiError{
+			Error:   "marcação bloqueada; reenvie com &force=true para bater mesmo assim",
+			Reasons: summary.BlockReasons,
+		})
+		return
+	}
+	if !a.consumeConfirmToken(token) {
+		newToken, expiresAt := a.issueConfirmToken()
+		writeJSON(w, http.StatusPreconditionRequired, punchConfirmation{
+			Message:      "confirme a marcação reenviando POST /punch?confirm=<confirmToken>",
+			ConfirmToken: newToken,
+			ExpiresAt:    expiresAt.Format(time.RFC3339),
+			Intent:       summary.Intent,
+			Details:      summary.Details,
+			Blocked:      summary.BlockReasons,
+		})
+		return
+	}
+
+	resp := a.daemon.dispatch(core.IPCRequest{Method: core.IPCMethodPunch})
+	if resp.Error != "" {
+		writeJSON(w, http.StatusBadGateway, apiError{Error: resp.Error})
+		return
+	}
+
+	a.daemon.mu.Lock()
+	msg := a.daemon.msg
+	a.daemon.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, punchResult{
+		DateEvent: resp.DateEvent,
+		TimeEvent: resp.TimeEvent,
+		Status:    buildStatusReport(msg, core.Now()),
+	})
+}
+
+func (a *apiServer) issueConfirmToken() (string, time.Time) {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	expiresAt := time.Now().Add(punchConfirmTTL)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for t, exp := range a.confirmTokens {
+		if time.Now().After(exp) {
+			delete(a.confirmTokens, t)
+		}
+	}
+	a.confirmTokens[token] = expiresAt
+
+	return token, expiresAt
+}
+
+func (a *apiServer) consumeConfirmToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	expiresAt, ok := a.confirmTokens[token]
+	delete(a.confirmTokens, token)
+	return ok && time.Now().Before(expiresAt)
+}
+
+// handleEvents mantém um stream SSE com os eventos "punches" (marcações
+// incluídas, alteradas ou removidas em um refresh, com o status de hoje) e
+// "alert" (alerta de intervalo).
+func (a *apiServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "streaming não suportado"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": conectado\n\n")
+	flusher.Flush()
+
+	ch := a.broker.subscribe()
+	defer a.broker.unsubscribe(ch)
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case ev := <-ch:
+			data, err := json.Marshal(ev.data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name, strings.ReplaceAll(string(data), "\n", ""))
+			flusher.Flush()
+		}
+	}
+}
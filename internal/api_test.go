@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPILocalOnly(t *testing.T) {
+	api := &apiServer{allowedOrigin: "http://localhost:3000", port: "7788"}
+	handler := api.withLocalOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		host   string
+		origin string
+		want   int
+	}{
+		{name: "127.0.0.1 na porta da API", method: http.MethodGet, host: "127.0.0.1:7788", want: http.StatusNoContent},
+		{name: "localhost", method: http.MethodGet, host: "localhost:7788", want: http.StatusNoContent},
+		{name: "localhost em maiúsculas", method: http.MethodGet, host: "LOCALHOST:7788", want: http.StatusNoContent},
+		{name: "IPv6 loopback", method: http.MethodGet, host: "[::1]:7788", want: http.StatusNoContent},
+		{name: "DNS rebinding", method: http.MethodGet, host: "evil.example:7788", want: http.StatusForbidden},
+		{name: "outra porta", method: http.MethodGet, host: "127.0.0.1:8080", want: http.StatusForbidden},
+		{name: "sem porta", method: http.MethodGet, host: "localhost", want: http.StatusForbidden},
+		{name: "outro IP de loopback", method: http.MethodGet, host: "127.0.0.2:7788", want: http.StatusForbidden},
+		{name: "leitura com origem qualquer", method: http.MethodGet, host: "localhost:7788", origin: "http://evil.example", want: http.StatusNoContent},
+		{name: "POST sem Origin (CLI)", method: http.MethodPost, host: "127.0.0.1:7788", want: http.StatusNoContent},
+		{name: "POST da origem liberada", method: http.MethodPost, host: "127.0.0.1:7788", origin: "http://localhost:3000", want: http.StatusNoContent},
+		{name: "POST de outra origem", method: http.MethodPost, host: "127.0.0.1:7788", origin: "http://evil.example:7788", want: http.StatusForbidden},
+		{name: "POST com Origin null", method: http.MethodPost, host: "127.0.0.1:7788", origin: "null", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/punch", nil)
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, esperado %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPILocalOnlyWithoutAllowedOrigin(t *testing.T) {
+	api := &apiServer{port: "7788"}
+	handler := api.withLocalOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/punch", nil)
+	req.Host = "127.0.0.1:7788"
+	req.Header.Set("Origin", "http://127.0.0.1:7788")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, esperado %d", rec.Code, http.StatusForbidden)
+	}
+}
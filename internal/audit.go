@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+// auditDisplayWindow limita os ajustes exibidos na aba Histórico aos
+// detectados recentemente; o livro local guarda todos.
+const auditDisplayWindow = 30 * 24 * time.Hour
+
+// auditsMsg traz os ajustes lidos do livro local para a aba Histórico.
+type auditsMsg struct{ audits []core.AuditEntry }
+
+// loadAudits lê do livro local os ajustes detectados em marcações passadas.
+// Decifra o livro inteiro, então fica restrito às telas que mostram os
+// ajustes (aba Histórico e relatórios de histórico).
+func loadAudits() []core.AuditEntry {
+	ledger, err := core.LoadLedger()
+	if err != nil {
+		log.Printf("Erro ao ler ajustes do livro de marcações: %v", err)
+		return nil
+	}
+	return ledger.Audits
+}
+
+// ensureAudits lê os ajustes em segundo plano quando a aba Histórico está
+// aberta e eles ainda não foram carregados.
+func ensureAudits(m *clockTimer) tea.Cmd {
+	if m.activeTab != 1 || m.auditsLoaded {
+		return nil
+	}
+	m.auditsLoaded = true
+	return func() tea.Msg {
+		return auditsMsg{audits: loadAudits()}
+	}
+}
+
+// recentAudits devolve os ajustes detectados dentro de auditDisplayWindow, do
+// mais recente ao mais antigo.
+func recentAudits(audits []core.AuditEntry, now time.Time) []core.AuditEntry {
+	var recent []core.AuditEntry
+	for i := len(audits) - 1; i >= 0; i-- {
+		if now.Sub(audits[i].DetectedAt) <= auditDisplayWindow {
+			recent = append(recent, audits[i])
+		}
+	}
+	return recent
+}
+
+// auditsForDate devolve os ajustes que afetaram a data (antiga ou nova).
+func auditsForDate(audits []core.AuditEntry, date string) []core.AuditEntry {
+	var result []core.AuditEntry
+	for _, a := range audits {
+		if a.DateEvent == date || a.OldDate == date {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// describeAudit descreve um ajuste em uma linha, ex.:
+// "14/10: marcação 12:00 alterada para 12:15".
+func describeAudit(a core.AuditEntry) string {
+	date := formatShortDateKey(a.DateEvent)
+	switch a.Kind {
+	case core.AuditInserted:
+		return fmt.Sprintf("%s: marcação %s incluída", date, shortTime(a.NewTime))
+	case core.AuditRemoved:
+		return fmt.Sprintf("%s: marcação %s removida", date, shortTime(a.OldTime))
+	case core.AuditChanged:
+		if a.OldDate != "" {
+			return fmt.Sprintf("%s: marcação de %s %s movida para %s",
+				date, formatShortDateKey(a.OldDate), shortTime(a.OldTime), shortTime(a.NewTime))
+		}
+		return fmt.Sprintf("%s: marcação %s alterada para %s",
+			date, shortTime(a.OldTime), shortTime(a.NewTime))
+	default:
+		return fmt.Sprintf("%s: ajuste %q", date, a.Kind)
+	}
+}
+
+// notifyAudits avisa sobre ajustes recém-detectados; bloqueia na chamada
+// D-Bus, então deve rodar em uma goroutine.
+func notifyAudits(audits []core.AuditEntry) {
+	message := describeAudit(audits[0])
+	if len(audits) > 1 {
+		message = fmt.Sprintf("%s (e mais %d)", message, len(audits)-1)
+	}
+	handleDesktopNotification("Marcação ajustada na Senior", message, "normal")
+}
+
+func formatShortDateKey(key string) string {
+	if t, ok := parseDateKey(key); ok {
+		return t.Format("02/01")
+	}
+	return key
+}
+
+// shortTime reduz o horário da Senior ("15:04:05.000") a "15:04".
+func shortTime(s string) string {
+	if len(s) >= 5 {
+		return s[:5]
+	}
+	return s
+}
@@ -0,0 +1,448 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+	"github.com/diegodario88/clockwerk/internal/ui"
+)
+
+// Códigos de saída dos subcomandos não interativos.
+const (
+	exitOK      = 0
+	exitFailure = 1 // falha de rede, da API ou inesperada
+	exitUsage   = 2 // argumentos inválidos
+	exitNoAuth  = 3 // sem credenciais salvas ou autenticação recusada
+	exitAborted = 4 // operação não confirmada pelo usuário
+)
+
+const cliUsage = `Uso: clockwerk [comando] [opções]
+
+Sem comando, abre a interface interativa (TUI).
+
+Comandos:
+  status [--output F]  mostra as marcações de hoje, o tempo trabalhado e a saída prevista
+  punch [--yes] [--force]
+                       registra uma marcação de ponto; --force ignora o
+                       intervalo mínimo e o limite de marcações do expediente
+  history [--week|--month|--from D --to D] [--output F]
+                       mostra o histórico de marcações e o saldo do período;
+                       --from/--to cobrem todo o livro local de marcações
+  statusline [--format text|waybar]
+                       linha compacta para tmux, waybar, polybar e prompts (lê só o cache)
+  daemon               roda em segundo plano e compartilha a sessão pelo socket local
+  metrics [--textfile ARQUIVO]
+                       métricas no formato Prometheus (ou textfile do node_exporter)
+  login [--domain D --cpf N [--password-stdin|--token-stdin]]
+                       autentica e salva as credenciais; com --token-stdin,
+                       usa um token obtido via SSO no navegador, sem senha
+  logout               esquece as credenciais salvas
+  gateway serve        sobe um gateway de login próprio (POST /senior/login)
+  help                 mostra esta ajuda
+
+Opções de leitura:
+  --output F           json, yaml ou table (padrão); JSON/YAML seguem um
+                       esquema versionado pelo campo schemaVersion
+
+Códigos de saída:
+  0 sucesso, 1 falha, 2 uso inválido, 3 sem autenticação, 4 operação cancelada
+`
+
+// RunCommand executa um subcomando não interativo e devolve o código de saída
+// do processo.
+func RunCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, cliUsage)
+		return exitUsage
+	}
+
+	// Ctrl+C cancela as requisições em andamento em vez de esperar o timeout.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args[0] {
+	case "status":
+		return runStatus(ctx, args[1:])
+	case "punch":
+		return runPunch(ctx, args[1:])
+	case "history":
+		return runHistory(ctx, args[1:])
+	case "statusline":
+		return runStatusline(args[1:])
+	case "daemon":
+		return runDaemon(ctx, args[1:])
+	case "metrics":
+		return runMetrics(ctx, args[1:])
+	case "login":
+		return runLogin(ctx, args[1:])
+	case "gateway":
+		return runGateway(ctx, args[1:])
+	case "logout":
+		return runLogout(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, cliUsage)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "comando desconhecido: %s\n\n%s", args[0], cliUsage)
+		return exitUsage
+	}
+}
+
+// newFlagSet cria um FlagSet que reporta erros sem encerrar o processo.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("clockwerk "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseExitCode traduz o erro de fs.Parse no código de saída: pedir a ajuda
+// (-h) não é uma falha.
+func parseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// exitCodeFor traduz o erro de uma sessão no código de saída correspondente.
+func exitCodeFor(err error) int {
+	if errors.Is(err, errNoCredentials) || errors.Is(err, senior.ErrUnauthorized) ||
+		errors.Is(err, senior.ErrTokenExpired) || errors.Is(err, senior.ErrTenantNotFound) {
+		return exitNoAuth
+	}
+	return exitFailure
+}
+
+func reportError(err error) int {
+	fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+	return exitCodeFor(err)
+}
+
+func runStatus(ctx context.Context, args []string) int {
+	fs := newFlagSet("status")
+	output := fs.String("output", "table", "formato de saída: json, yaml ou table")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	format, err := parseOutputFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	session, err := newSession(ctx)
+	if err != nil {
+		return reportError(err)
+	}
+
+	msg, err := session.events()
+	if err != nil {
+		return reportError(err)
+	}
+
+	if warning, ok := core.ClockSkewWarning(); ok {
+		fmt.Fprintf(os.Stderr, "aviso: %s; horários pelo relógio da Senior\n", warning)
+	}
+
+	report := buildStatusReport(msg, core.Now())
+	if err := writeReport(os.Stdout, format, report, report.writeTable); err != nil {
+		return reportError(err)
+	}
+
+	return exitOK
+}
+
+func runPunch(ctx context.Context, args []string) int {
+	fs := newFlagSet("punch")
+	yes := fs.Bool("yes", false, "registra sem pedir confirmação")
+	force := fs.Bool("force", false, "registra mesmo com a marcação bloqueada")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	session, err := newSession(ctx)
+	if err != nil {
+		return reportError(err)
+	}
+
+	msg, err := session.events()
+	if err != nil {
+		return reportError(err)
+	}
+
+	summary := summarizePunch(msg, core.Now())
+	question := fmt.Sprintf("Registrar %s às %s?", summary.Intent, summary.At.Format("15:04"))
+	if !*yes {
+		for _, detail := range summary.Details {
+			fmt.Fprintf(os.Stderr, "%s\n", detail)
+		}
+	}
+	if len(summary.BlockReasons) > 0 && !*force {
+		fmt.Fprintf(os.Stderr, "%s bloqueada:\n", summary.Intent)
+		for _, reason := range summary.BlockReasons {
+			fmt.Fprintf(os.Stderr, "  - %s\n", reason)
+		}
+		if *yes {
+			fmt.Fprintln(os.Stderr, "use --force para bater mesmo assim")
+			return exitAborted
+		}
+		question = fmt.Sprintf("Registrar %s mesmo assim?", summary.Intent)
+	}
+
+	if !*yes && !askConfirmation(os.Stdin, os.Stderr, question) {
+		fmt.Fprintln(os.Stderr, "marcação cancelada")
+		return exitAborted
+	}
+
+	started := core.Now()
+	punched, err := session.punch(msg)
+	if err != nil {
+		if !punchMayHaveLanded(err) {
+			return reportError(err)
+		}
+		// A requisição saiu sem resposta: confere antes de dar a marcação
+		// como perdida, para que ela não seja repetida à toa.
+		fmt.Fprintf(os.Stderr, "sem resposta da Senior (%v), conferindo se o ponto foi registrado...\n", err)
+		c, found, ferr := session.findPunch(msg, PostClockingMsg{}, started)
+		switch {
+		case ferr != nil:
+			fmt.Fprintf(os.Stderr, "não foi possível conferir (%v); veja as marcações com `clockwerk status` antes de repetir\n", ferr)
+			return reportError(err)
+		case !found:
+			fmt.Fprintln(os.Stderr, "o ponto não foi registrado")
+			return reportError(err)
+		}
+		fmt.Fprintf(os.Stdout, "Ponto registrado às %s\n", c.eventTime.Format("15:04"))
+		return exitOK
+	}
+
+	c, found, err := session.findPunch(msg, punched, started)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "aviso: falha ao conferir a marcação: %v\n", err)
+	}
+	if !found {
+		fmt.Fprintf(os.Stdout, "Ponto enviado às %s; ainda não aparece na Senior\n",
+			punchedTime(punchFlow{punched: punched, startedAt: started}).Format("15:04"))
+		return exitOK
+	}
+	fmt.Fprintf(os.Stdout, "Ponto registrado às %s\n", c.eventTime.Format("15:04"))
+	return exitOK
+}
+
+// askConfirmation pergunta sim/não em r; qualquer resposta diferente de
+// "s"/"sim" (inclusive EOF) é tratada como não.
+func askConfirmation(r io.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [s/N] ", question)
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(w)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "s", "sim", "y", "yes":
+		return true
+	}
+	return false
+}
+
+func runHistory(ctx context.Context, args []string) int {
+	fs := newFlagSet("history")
+	week := fs.Bool("week", false, "últimos cinco dias úteis com marcações (padrão)")
+	month := fs.Bool("month", false, "dias do mês atual com marcações")
+	from := fs.String("from", "", "primeiro dia do período (AAAA-MM-DD)")
+	to := fs.String("to", "", "último dia do período (AAAA-MM-DD)")
+	output := fs.String("output", "table", "formato de saída: json, yaml ou table")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	isRange := *from != "" || *to != ""
+	if (*week && *month) || (isRange && (*week || *month)) {
+		fmt.Fprintln(os.Stderr, "use apenas uma das opções --week, --month ou --from/--to")
+		return exitUsage
+	}
+	for _, key := range []string{*from, *to} {
+		if _, ok := parseDateKey(key); key != "" && !ok {
+			fmt.Fprintf(os.Stderr, "data inválida %q (use AAAA-MM-DD)\n", key)
+			return exitUsage
+		}
+	}
+	format, err := parseOutputFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	session, err := newSession(ctx)
+	if err != nil {
+		return reportError(err)
+	}
+
+	msg, err := session.events()
+	if err != nil {
+		return reportError(err)
+	}
+
+	period := "week"
+	if *month {
+		period = "month"
+	}
+
+	var report historyReport
+	if isRange {
+		msg, err = session.eventsInRange(msg, *from, *to)
+		if err != nil {
+			return reportError(err)
+		}
+		report = buildRangeReport(msg, loadAudits(), *from, *to, core.Now())
+	} else {
+		report = buildHistoryReport(msg, loadAudits(), period, core.Now())
+	}
+	if err := writeReport(os.Stdout, format, report, report.writeTable); err != nil {
+		return reportError(err)
+	}
+
+	return exitOK
+}
+
+func runLogin(ctx context.Context, args []string) int {
+	fs := newFlagSet("login")
+	domain := fs.String("domain", "", "domínio da empresa (ex.: exemplo.com.br)")
+	cpf := fs.String("cpf", "", "CPF, apenas números")
+	passwordStdin := fs.Bool("password-stdin", false, "lê a senha da entrada padrão")
+	tokenStdin := fs.Bool("token-stdin", false, "lê da entrada padrão um token obtido via SSO, sem senha")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if *passwordStdin && *tokenStdin {
+		fmt.Fprintln(os.Stderr, "erro: use --password-stdin ou --token-stdin, não ambos")
+		return exitUsage
+	}
+
+	creds := core.UserCredentials{Domain: *domain, CPF: *cpf}
+
+	// A entrada padrão já traz a senha ou o token, então o formulário não
+	// tem de onde ler domínio e CPF.
+	if (*passwordStdin || *tokenStdin) && (creds.Domain == "" || creds.CPF == "") {
+		fmt.Fprintln(os.Stderr, "erro: --password-stdin e --token-stdin exigem --domain e --cpf")
+		return exitUsage
+	}
+
+	sso := *tokenStdin
+	if creds.Domain == "" || creds.CPF == "" {
+		form := ui.NewCPFForm(creds.Domain, creds.CPF, sso)
+		if err := form.Run(); err != nil || !form.GetBool("next") {
+			return exitAborted
+		}
+		creds.Domain = form.GetString("domain")
+		creds.CPF = form.GetString("cpf")
+		sso = form.GetBool("sso")
+	}
+	creds.Domain = senior.TenantFromDomain(creds.Domain)
+	if err := senior.ValidateTenant(creds.Domain); err != nil {
+		fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+		return exitUsage
+	}
+	core.SetTenantDomain(creds.Domain)
+
+	if sso {
+		var token string
+		if *tokenStdin {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return reportError(err)
+			}
+			token = string(data)
+		} else {
+			form := ui.NewTokenForm()
+			if err := form.Run(); err != nil || !form.GetBool("next") {
+				return exitAborted
+			}
+			token = form.GetString("token")
+		}
+		return loginWithToken(ctx, creds, token)
+	}
+
+	if *passwordStdin {
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			fmt.Fprintln(os.Stderr, "erro: senha não informada na entrada padrão")
+			return exitUsage
+		}
+		creds.Password = strings.TrimRight(password, "\r\n")
+	} else {
+		form := ui.NewPasswordForm("")
+		if err := form.Run(); err != nil || !form.GetBool("next") {
+			return exitAborted
+		}
+		creds.Password = form.GetString("password")
+	}
+
+	token, err := core.Login(ctx, fmt.Sprintf("%s@%s", creds.CPF, creds.Domain), creds.Password)
+	if err == nil {
+		// Confere que o tenant derivado do domínio existe.
+		err = core.ValidateToken(ctx, token)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+		return exitNoAuth
+	}
+	creds.Token = token
+
+	if err := core.SaveCredentials(creds); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Credenciais salvas em %s\n", core.GetCredentialsFilePath())
+	return exitOK
+}
+
+// loginWithToken valida um token obtido via SSO e o salva sem senha. Quando
+// ele expirar, é preciso repetir o comando com um novo token.
+func loginWithToken(ctx context.Context, creds core.UserCredentials, token string) int {
+	creds.Token = core.NormalizeBearerToken(token)
+	if creds.Token == "" {
+		fmt.Fprintln(os.Stderr, "erro: token não informado")
+		return exitUsage
+	}
+
+	if err := core.ValidateToken(ctx, creds.Token); err != nil {
+		fmt.Fprintf(os.Stderr, "erro: token recusado: %v\n", err)
+		return exitNoAuth
+	}
+
+	if err := core.SaveCredentials(creds); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Token salvo em %s\n", core.GetCredentialsFilePath())
+	if expiry, ok := core.TokenExpiry(creds.Token); ok {
+		fmt.Fprintf(os.Stdout, "Válido até %s; depois, repita o login com um novo token.\n", expiry.Format("02/01/2006 15:04"))
+	}
+	return exitOK
+}
+
+func runLogout(args []string) int {
+	fs := newFlagSet("logout")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	if err := core.DeleteCredentials(); err != nil {
+		return reportError(err)
+	}
+	if err := core.DeleteEventCache(); err != nil {
+		return reportError(err)
+	}
+
+	fmt.Fprintln(os.Stdout, "Credenciais removidas.")
+	return exitOK
+}
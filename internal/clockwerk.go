@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -49,13 +50,32 @@ type eventMsg struct {
 }
 
 type clockTimer struct {
+	// ctx é cancelado quando a TUI encerra, interrompendo as requisições em
+	// andamento.
+	ctx              context.Context
 	step             int
 	punchCount       int
 	activeTab        int
 	historyView      int
-	keepLogged       bool
-	timerRunning     bool
-	tickScheduled    bool
+	historyOffset    int
+	historyLoading   bool
+	historyError     string
+	historyRequested map[string]bool
+	// audits são os ajustes do livro local exibidos no Histórico, lidos só
+	// quando a aba é aberta (ver ensureAudits); auditsLoaded fica falso
+	// quando uma busca pode ter detectado novos.
+	audits       []core.AuditEntry
+	auditsLoaded bool
+	keepLogged   bool
+	// sso indica login por token colado do navegador, sem senha;
+	// tokenExpired avisa, no formulário do token, que o anterior expirou.
+	sso           bool
+	tokenExpired  bool
+	timerRunning  bool
+	tickScheduled bool
+	// tickScheduledAt é o horário (sem leitura monotônica) em que o tick
+	// pendente foi agendado, para detectar a volta de uma suspensão.
+	tickScheduledAt  time.Time
 	refreshing       bool
 	refreshScheduled bool
 	hasAuthRecover   bool
@@ -66,25 +86,40 @@ type clockTimer struct {
 	token            string
 	cpfForm          *huh.Form
 	passwordForm     *huh.Form
+	tokenForm        *huh.Form
 	keepForm         *huh.Form
 	punchForm        *huh.Form
 	forgetForm       *huh.Form
 	failedMsg        FailedMsg
+	punch            punchFlow
+	punchReceipt     *punchReceipt
 	loginMsg         LoginMsg
 	eventMsg         eventMsg
 	spinner          spinner.Model
 	paginator        paginator.Model
 	elapsed          time.Duration
+	// today é a chave do dia exibido como hoje; quando deixa de ser a de
+	// core.TodayKey, a sessão vira o dia (ver rolloverDay).
+	today            string
+	fetchedAt        time.Time
+	stale            bool
 	nextRefresh      time.Time
+	tokenRenewals    []core.TokenRenewal
+	retryAttempt     int
+	retryGen         int
+	retryErr         error
+	retryAt          time.Time
 	lastNotification time.Time
-	help             help.Model
-	keys             keyMap
-	width            int
-	height           int
-	tooSmall         bool
+	// resumed recebe os avisos de retomada do logind (nil sem D-Bus).
+	resumed  <-chan struct{}
+	help     help.Model
+	keys     keyMap
+	width    int
+	height   int
+	tooSmall bool
 }
 
-func NewClockTimer() clockTimer {
+func NewClockTimer(ctx context.Context) clockTimer {
 	sp := spinner.New()
 	sp.Spinner = spinner.Points
 	sp.Style = lipgloss.NewStyle().
@@ -111,8 +146,8 @@ func NewClockTimer() clockTimer {
 	initialPassword := ""
 	initialToken := ""
 
-	if err == nil && creds.Domain != "" && creds.CPF != "" && creds.Password != "" &&
-		creds.Token != "" {
+	// Sem senha, as credenciais vieram de um login por token (SSO).
+	if err == nil && creds.Domain != "" && creds.CPF != "" && creds.Token != "" {
 		initialStep = 4
 		initialDomain = creds.Domain
 		initialCPF = creds.CPF
@@ -120,28 +155,48 @@ func NewClockTimer() clockTimer {
 		initialToken = creds.Token
 	}
 
-	return clockTimer{
-		step:         initialStep,
-		domain:       initialDomain,
-		cpf:          initialCPF,
-		password:     initialPassword,
-		token:        initialToken,
-		cpfForm:      ui.NewCPFForm(initialDomain, initialCPF),
-		passwordForm: ui.NewPasswordForm(initialPassword),
-		keepForm:     ui.NewKeepForm(true),
-		punchForm:    nil,
-		forgetForm:   nil,
-		spinner:      sp,
-		paginator:    p,
-		timerRunning: false,
-		elapsed:      0,
-		punchCount:   0,
-		keepLogged:   true,
-		help:         helpModel,
-		keys:         keys,
-		activeTab:    0,
-		historyView:  0,
+	m := clockTimer{
+		ctx:           ctx,
+		step:          initialStep,
+		domain:        initialDomain,
+		cpf:           initialCPF,
+		password:      initialPassword,
+		token:         initialToken,
+		tokenRenewals: creds.Renewals,
+		sso:           initialStep == 4 && initialPassword == "",
+		cpfForm:       ui.NewCPFForm(initialDomain, initialCPF, initialStep == 4 && initialPassword == ""),
+		passwordForm:  ui.NewPasswordForm(initialPassword),
+		tokenForm:     ui.NewTokenForm(),
+		keepForm:      ui.NewKeepForm(true),
+		punchForm:     nil,
+		forgetForm:    nil,
+		spinner:       sp,
+		paginator:     p,
+		timerRunning:  false,
+		elapsed:       0,
+		punchCount:    0,
+		keepLogged:    true,
+		help:          helpModel,
+		keys:          keys,
+		activeTab:     0,
+		historyView:   0,
+		resumed:       watchResume(ctx),
 	}
+
+	// Com credenciais e um cache local, abre direto o dashboard com os dados
+	// salvos (marcados como desatualizados) e atualiza em segundo plano.
+	if initialStep == 4 {
+		if msg, fetchedAt, ok := loadCachedEventMsg(); ok {
+			m.step = 5
+			applyEventMsg(&m, msg)
+			m.fetchedAt = fetchedAt
+			m.stale = true
+			m.refreshing = true
+			m.tickScheduled = true
+		}
+	}
+
+	return m
 }
 
 func (m clockTimer) Init() tea.Cmd {
@@ -157,12 +212,20 @@ func (m clockTimer) Init() tea.Cmd {
 		Background(lipgloss.Color(core.AmberFlare))
 
 	if m.step == 0 {
-		return m.cpfForm.Init()
+		return tea.Batch(m.cpfForm.Init(), waitResume(m.resumed))
 	} else if m.step == 4 {
-		return tea.Batch(handleGetClockingEvent(m.token), m.spinner.Tick)
+		return tea.Batch(fetchEventsCmd(&m), m.spinner.Tick, waitResume(m.resumed))
+	} else if m.step == 5 {
+		// Iniciado a partir do cache: NewClockTimer já marcou tickScheduled,
+		// então o tick é criado aqui diretamente.
+		return tea.Batch(
+			fetchEventsCmd(&m),
+			tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg{} }),
+			waitResume(m.resumed),
+		)
 	}
 
-	return nil
+	return waitResume(m.resumed)
 }
 
 func (m clockTimer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -176,6 +239,16 @@ func (m clockTimer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if cmd, ok := dispatchRetry(msg, &m); ok {
+		return m, cmd
+	}
+	if cmd, ok := dispatchTokenRenewal(msg, &m); ok {
+		return m, cmd
+	}
+	if cmd, ok := dispatchResume(msg, &m); ok {
+		return m, cmd
+	}
+
 	switch m.step {
 	case 0:
 		return dispatchInputCpf(msg, &m)
@@ -205,6 +278,9 @@ func (m clockTimer) View() string {
 	case 0:
 		return renderCpfStep(&m)
 	case 1:
+		if m.sso {
+			return renderTokenStep(&m)
+		}
 		return renderPasswordStep(&m)
 	case 2:
 		return renderKeepStep(&m)
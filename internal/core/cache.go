@@ -0,0 +1,43 @@
+package core
+
+import (
+	"time"
+)
+
+// EventCache é o último retorno de GetClockingEvents persistido em disco, para
+// que statusline e afins leiam o estado sem depender da rede.
+type EventCache struct {
+	FetchedAt time.Time       `json:"fetchedAt"`
+	Events    []ClockingEvent `json:"events"`
+	// ClockSkew é a diferença de relógio estimada na busca (ver ClockSkew).
+	ClockSkew time.Duration `json:"clockSkew,omitempty"`
+}
+
+func SaveEventCache(events []ClockingEvent) error {
+	skew, _ := ClockSkew()
+	return writeEncryptedJSON(GetEventCacheFilePath(), EventCache{
+		FetchedAt: time.Now(),
+		Events:    events,
+		ClockSkew: skew,
+	}, "cache de eventos")
+}
+
+// LoadEventCache devolve o cache salvo; sem cache, retorna um EventCache vazio
+// (FetchedAt zero) e nenhum erro. A diferença de relógio salva vale até a
+// primeira resposta da Senior neste processo.
+func LoadEventCache() (EventCache, error) {
+	var cache EventCache
+	err := readEncryptedJSON(GetEventCacheFilePath(), &cache, "cache de eventos")
+	if err == nil && cache.ClockSkew != 0 {
+		adoptClockSkew(cache.ClockSkew)
+	}
+	return cache, err
+}
+
+func DeleteEventCache() error {
+	return removeFile(GetEventCacheFilePath(), "cache de eventos")
+}
+
+func GetEventCacheFilePath() string {
+	return homeFilePath(".clockwerk_cache.enc")
+}
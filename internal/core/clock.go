@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// ClockSkewThreshold é a diferença entre o relógio local e o da Senior a
+	// partir da qual o usuário é avisado.
+	ClockSkewThreshold = time.Minute
+	// clockSkewMaxRTT descarta amostras de respostas lentas, em que a
+	// incerteza passa da resolução de 1s do cabeçalho Date.
+	clockSkewMaxRTT = 5 * time.Second
+	// clockSkewWeight é o peso de cada nova amostra na média móvel.
+	clockSkewWeight = 0.25
+)
+
+var (
+	clockMu       sync.Mutex
+	clockSkew     time.Duration
+	clockKnown    bool // há uma estimativa (própria, do daemon ou do cache)
+	clockMeasured bool // a estimativa vem de respostas recebidas por este processo
+)
+
+// observeServerDate atualiza a estimativa da diferença de relógio com o
+// cabeçalho Date de uma resposta da Senior recebida em receivedAt, rtt depois
+// do envio.
+func observeServerDate(resp *http.Response, rtt time.Duration, receivedAt time.Time) {
+	if resp == nil || rtt > clockSkewMaxRTT {
+		return
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	// Date é truncado ao segundo: o meio do segundo é comparado ao meio da
+	// requisição.
+	sample := date.Add(500 * time.Millisecond).Sub(receivedAt.Add(-rtt / 2))
+
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	if !clockMeasured {
+		clockSkew = sample
+	} else {
+		clockSkew += time.Duration(float64(sample-clockSkew) * clockSkewWeight)
+	}
+	clockKnown, clockMeasured = true, true
+}
+
+// adoptClockSkew usa a estimativa de outro processo (daemon, cache) enquanto
+// este não tiver amostras próprias.
+func adoptClockSkew(skew time.Duration) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	if !clockMeasured {
+		clockSkew, clockKnown = skew, true
+	}
+}
+
+// ClockSkew devolve quanto o relógio da Senior está à frente do local
+// (negativo quando está atrás). ok é falso sem nenhuma estimativa.
+func ClockSkew() (skew time.Duration, ok bool) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	return clockSkew, clockKnown
+}
+
+// Now devolve o horário atual pelo relógio da Senior, que carimba as
+// marcações. Sem estimativa, é o relógio local.
+func Now() time.Time {
+	skew, _ := ClockSkew()
+	return time.Now().Add(skew)
+}
+
+// DateKey devolve a chave de data das marcações ("2006-01-02") de t no fuso
+// local.
+func DateKey(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// TodayKey devolve a chave de hoje pelo relógio da Senior. É calculada a cada
+// chamada, então processos longos (TUI, daemon) viram o dia à meia-noite.
+func TodayKey() string {
+	return DateKey(Now())
+}
+
+// ClockSkewWarning descreve a diferença de relógio quando ela passa de
+// ClockSkewThreshold, ex.: "relógio local 3m12s adiantado em relação à
+// Senior".
+func ClockSkewWarning() (string, bool) {
+	skew, ok := ClockSkew()
+	if !ok || (skew < ClockSkewThreshold && skew > -ClockSkewThreshold) {
+		return "", false
+	}
+
+	direction := "atrasado"
+	if skew < 0 {
+		direction = "adiantado"
+		skew = -skew
+	}
+	return fmt.Sprintf("relógio local %s %s em relação à Senior", formatShortDuration(skew), direction), true
+}
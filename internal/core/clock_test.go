@@ -0,0 +1,64 @@
+package core
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// resetClockSkew descarta a estimativa de relógio, restaurando-a no fim do
+// teste.
+func resetClockSkew(t *testing.T) {
+	clockMu.Lock()
+	skew, known, measured := clockSkew, clockKnown, clockMeasured
+	clockSkew, clockKnown, clockMeasured = 0, false, false
+	clockMu.Unlock()
+
+	t.Cleanup(func() {
+		clockMu.Lock()
+		clockSkew, clockKnown, clockMeasured = skew, known, measured
+		clockMu.Unlock()
+	})
+}
+
+func TestObserveAPICallsSkewSource(t *testing.T) {
+	observe := observeAPICalls("https://platform.senior.com.br")
+	ahead := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		name  string
+		url   string
+		known bool
+	}{
+		{name: "plataforma", url: "https://platform.senior.com.br/t/empresa.com.br/bridge/1.0/rest/x", known: true},
+		{name: "host em maiúsculas", url: "https://Platform.Senior.com.br/t/x", known: true},
+		{name: "gateway de login", url: "https://gateway.empresa.com.br/senior/login"},
+		{name: "host que começa com o da plataforma", url: "https://platform.senior.com.br.evil.example/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetClockSkew(t)
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Date": {ahead}},
+				Request:    &http.Request{URL: u},
+			}
+
+			observe("login", resp, nil, 100*time.Millisecond)
+
+			skew, known := ClockSkew()
+			if known != tt.known {
+				t.Fatalf("estimativa conhecida = %v, esperado %v", known, tt.known)
+			}
+			if known && (skew < 59*time.Minute || skew > 61*time.Minute) {
+				t.Errorf("diferença = %v, esperado cerca de 1h", skew)
+			}
+		})
+	}
+}
@@ -1,14 +1,9 @@
 package core
 
-import (
-	"time"
-
-	"github.com/charmbracelet/huh"
-)
+import "github.com/charmbracelet/huh"
 
 var (
 	Theme          *huh.Theme = huh.ThemeBase()
-	TodayKey                  = time.Now().Local().Format("2006-01-02")
 	DefaultConfirm            = true
 	Version                   = "development"
 )
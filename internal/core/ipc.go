@@ -0,0 +1,200 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+// Métodos aceitos pelo socket do daemon.
+const (
+	IPCMethodEvents  = "events"  // últimos eventos conhecidos pelo daemon
+	IPCMethodRefresh = "refresh" // força uma busca na Senior antes de responder
+	IPCMethodPunch   = "punch"   // registra uma marcação e atualiza os eventos
+	IPCMethodRange   = "range"   // busca na Senior os eventos de From a To
+)
+
+// IPCRequest é uma linha JSON enviada ao daemon; cada conexão carrega uma
+// única requisição.
+type IPCRequest struct {
+	Method string `json:"method"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// IPCResponse é a resposta do daemon. Events e FetchedAt acompanham todos os
+// métodos bem-sucedidos; DateEvent/TimeEvent apenas o punch.
+type IPCResponse struct {
+	Error string `json:"error,omitempty"`
+	// ErrorCode classifica Error (ver ipcErrorCodes) para que o cliente
+	// reconstrua o erro tipado da Senior; ErrorStatus acompanha o código
+	// "server".
+	ErrorCode   string    `json:"errorCode,omitempty"`
+	ErrorStatus int       `json:"errorStatus,omitempty"`
+	FetchedAt   time.Time `json:"fetchedAt"`
+	// ClockSkew é a diferença de relógio estimada pelo daemon (ver
+	// ClockSkew), adotada pelo cliente que não fala com a Senior.
+	ClockSkew time.Duration   `json:"clockSkew,omitempty"`
+	Events    []ClockingEvent `json:"events,omitempty"`
+	DateEvent string          `json:"dateEvent,omitempty"`
+	TimeEvent string          `json:"timeEvent,omitempty"`
+}
+
+// ipcErrorCodes associa os erros tipados da Senior ao código trafegado no
+// socket.
+var ipcErrorCodes = []struct {
+	code string
+	err  error
+}{
+	{"unauthorized", senior.ErrUnauthorized},
+	{"token_expired", senior.ErrTokenExpired},
+	{"rate_limited", senior.ErrRateLimited},
+	{"network", senior.ErrNetwork},
+	{"proxy_auth", senior.ErrProxyAuth},
+	{"proxy", senior.ErrProxy},
+	{"tls", senior.ErrTLS},
+	{"transport_config", senior.ErrTransportConfig},
+	{"decode", senior.ErrDecode},
+	{"tenant_not_found", senior.ErrTenantNotFound},
+}
+
+// IPCErrorResponse monta a resposta de erro do daemon, preservando o tipo do
+// erro da Senior.
+func IPCErrorResponse(err error) IPCResponse {
+	resp := IPCResponse{Error: err.Error()}
+
+	var serverErr *senior.ErrServer
+	if errors.As(err, &serverErr) {
+		resp.ErrorCode = "server"
+		resp.ErrorStatus = serverErr.Status
+		return resp
+	}
+	for _, c := range ipcErrorCodes {
+		if errors.Is(err, c.err) {
+			resp.ErrorCode = c.code
+			break
+		}
+	}
+	return resp
+}
+
+// remoteError é um erro reportado pelo daemon: mantém a mensagem original e
+// desembrulha para o erro tipado correspondente, quando houver.
+type remoteError struct {
+	message string
+	kind    error
+}
+
+func (e *remoteError) Error() string { return e.message }
+func (e *remoteError) Unwrap() error { return e.kind }
+
+func (resp IPCResponse) err() error {
+	if resp.ErrorCode == "server" {
+		return &remoteError{message: resp.Error, kind: &senior.ErrServer{Status: resp.ErrorStatus}}
+	}
+	for _, c := range ipcErrorCodes {
+		if c.code == resp.ErrorCode {
+			return &remoteError{message: resp.Error, kind: c.err}
+		}
+	}
+	return errors.New(resp.Error)
+}
+
+// ErrDaemonUnavailable indica que não há daemon escutando no socket.
+var ErrDaemonUnavailable = errors.New("daemon indisponível")
+
+// ErrDaemonNoResponse indica que a requisição foi entregue ao daemon, mas a
+// resposta não chegou (ex.: prazo esgotado): ele pode tê-la executado.
+var ErrDaemonNoResponse = errors.New("sem resposta do daemon")
+
+// GetSocketPath devolve o caminho do socket Unix do daemon, preferindo o
+// diretório de runtime do usuário (XDG_RUNTIME_DIR).
+func GetSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "clockwerk.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("clockwerk-%d.sock", os.Getuid()))
+}
+
+// DaemonRunning informa se há um daemon aceitando conexões no socket.
+func DaemonRunning() bool {
+	conn, err := net.DialTimeout("unix", GetSocketPath(), 200*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// DaemonRequest envia method ao daemon e aguarda a resposta. Erros reportados
+// pelo daemon voltam como error, com o mesmo tipo que o cliente da Senior
+// devolveria; sem daemon, retorna ErrDaemonUnavailable.
+func DaemonRequest(method string) (IPCResponse, error) {
+	// Refresh e punch dependem da Senior; o prazo cobre o timeout HTTP do
+	// daemon com folga.
+	return daemonCall(IPCRequest{Method: method}, 30*time.Second)
+}
+
+// DaemonRange pede ao daemon os eventos de from a to (chaves "2006-01-02").
+// A busca pode percorrer várias páginas, então o prazo é maior.
+func DaemonRange(from, to string) (IPCResponse, error) {
+	return daemonCall(IPCRequest{Method: IPCMethodRange, From: from, To: to}, 2*time.Minute)
+}
+
+func daemonCall(req IPCRequest, timeout time.Duration) (IPCResponse, error) {
+	conn, err := net.DialTimeout("unix", GetSocketPath(), 200*time.Millisecond)
+	if err != nil {
+		return IPCResponse{}, ErrDaemonUnavailable
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return IPCResponse{}, fmt.Errorf("erro ao enviar requisição ao daemon: %w", err)
+	}
+
+	var resp IPCResponse
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return IPCResponse{}, fmt.Errorf("%w: %w", ErrDaemonNoResponse, err)
+	}
+	if resp.Error != "" {
+		return resp, resp.err()
+	}
+	if resp.ClockSkew != 0 {
+		adoptClockSkew(resp.ClockSkew)
+	}
+
+	return resp, nil
+}
+
+// ListenDaemonSocket abre o socket do daemon, removendo um socket órfão
+// deixado por uma execução anterior. Falha se outro daemon já estiver ativo.
+func ListenDaemonSocket() (net.Listener, error) {
+	path := GetSocketPath()
+
+	if DaemonRunning() {
+		return nil, fmt.Errorf("já existe um daemon ativo em %s", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("erro ao remover socket antigo: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir socket %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("erro ao restringir permissões do socket: %w", err)
+	}
+
+	return listener, nil
+}
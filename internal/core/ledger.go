@@ -0,0 +1,382 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// LedgerEntry é uma marcação registrada no livro local. Guarda só o necessário
+// para reconstruir o histórico; os dados do colaborador vêm sempre da busca
+// mais recente. Removed marca uma marcação que deixou de existir na Senior.
+type LedgerEntry struct {
+	ID         string    `json:"id"`
+	DateEvent  string    `json:"dateEvent"`
+	TimeEvent  string    `json:"timeEvent"`
+	TimeZone   string    `json:"timeZone"`
+	Platform   string    `json:"platform"`
+	Removed    bool      `json:"removed,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+func (e LedgerEntry) ClockingEvent() ClockingEvent {
+	return ClockingEvent{
+		ID:        e.ID,
+		DateEvent: e.DateEvent,
+		TimeEvent: e.TimeEvent,
+		TimeZone:  e.TimeZone,
+		Platform:  e.Platform,
+	}
+}
+
+// Tipos de ajuste detectados em marcações já conhecidas.
+const (
+	AuditInserted = "inserted"
+	AuditChanged  = "changed"
+	AuditRemoved  = "removed"
+)
+
+// AuditEntry registra um ajuste feito do lado da Senior (normalmente pelo RH)
+// em marcações já vistas pelo Clockwerk.
+type AuditEntry struct {
+	Kind       string    `json:"kind"`
+	ID         string    `json:"id"`
+	DateEvent  string    `json:"dateEvent"`
+	OldDate    string    `json:"oldDate,omitempty"`
+	OldTime    string    `json:"oldTime,omitempty"`
+	NewTime    string    `json:"newTime,omitempty"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// Ledger é o estado reconstruído a partir do livro local.
+type Ledger struct {
+	// Entries traz a versão mais recente de cada marcação ainda existente, na
+	// ordem em que foram vistas pela primeira vez.
+	Entries []LedgerEntry
+	Audits  []AuditEntry
+	// LastSync é o início do último dia em que houve sincronização.
+	LastSync time.Time
+}
+
+// ledgerRecord é o conteúdo de cada linha do livro: uma marcação, um ajuste
+// ou um marcador de sincronização (gravado no máximo uma vez por dia).
+type ledgerRecord struct {
+	*LedgerEntry
+	Audit    *AuditEntry `json:"audit,omitempty"`
+	SyncedAt *time.Time  `json:"syncedAt,omitempty"`
+}
+
+// MergeLedger compara events com o livro local (append-only), registra as
+// marcações novas e os ajustes detectados, e devolve events seguido das
+// marcações conhecidas apenas pelo livro, junto com os ajustes desta
+// sincronização. Assim o histórico cobre além da janela de 200 eventos da API.
+func MergeLedger(events []ClockingEvent) ([]ClockingEvent, []AuditEntry, error) {
+	ledger, err := LoadLedger()
+	if err != nil {
+		return events, nil, err
+	}
+
+	now := time.Now()
+	records, audits := diffLedger(ledger, events, now)
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if ledger.LastSync.Before(today) {
+		records = append(records, ledgerRecord{SyncedAt: &today})
+	}
+
+	removed := make(map[string]bool)
+	for _, audit := range audits {
+		if audit.Kind == AuditRemoved {
+			removed[audit.ID] = true
+		}
+	}
+	var entries []LedgerEntry
+	for _, entry := range ledger.Entries {
+		if !removed[entry.ID] {
+			entries = append(entries, entry)
+		}
+	}
+
+	merged := withEntries(events, entries)
+
+	if err := appendLedger(records); err != nil {
+		return merged, audits, err
+	}
+
+	return merged, audits, nil
+}
+
+// diffLedger devolve as linhas a acrescentar ao livro e os ajustes detectados.
+// Contam como ajuste:
+//   - data ou hora diferente para uma marcação já conhecida;
+//   - marcação que sumiu de um dia passado coberto pela busca (o dia mais
+//     antigo retornado fica de fora, pois pode ter vindo cortado, assim como
+//     os dias depois do mais recente, em buscas por período);
+//   - marcação nova em um dia passado que já tinha marcações e que já havia
+//     sido sincronizado depois de encerrado.
+func diffLedger(ledger Ledger, events []ClockingEvent, now time.Time) ([]ledgerRecord, []AuditEntry) {
+	todayKey := now.Format("2006-01-02")
+	lastSyncKey := ""
+	if !ledger.LastSync.IsZero() {
+		lastSyncKey = ledger.LastSync.Format("2006-01-02")
+	}
+
+	known := make(map[string]LedgerEntry, len(ledger.Entries))
+	knownDates := make(map[string]bool)
+	for _, entry := range ledger.Entries {
+		known[entry.ID] = entry
+		knownDates[entry.DateEvent] = true
+	}
+
+	var records []ledgerRecord
+	var audits []AuditEntry
+
+	fetched := make(map[string]bool, len(events))
+	earliest, latest := "", ""
+	for _, event := range events {
+		fetched[event.ID] = true
+		if earliest == "" || event.DateEvent < earliest {
+			earliest = event.DateEvent
+		}
+		if event.DateEvent > latest {
+			latest = event.DateEvent
+		}
+
+		entry := LedgerEntry{
+			ID:         event.ID,
+			DateEvent:  event.DateEvent,
+			TimeEvent:  event.TimeEvent,
+			TimeZone:   event.TimeZone,
+			Platform:   event.Platform,
+			RecordedAt: now,
+		}
+
+		old, ok := known[event.ID]
+		switch {
+		case !ok:
+			if event.DateEvent < todayKey && event.DateEvent < lastSyncKey && knownDates[event.DateEvent] {
+				audits = append(audits, AuditEntry{
+					Kind:       AuditInserted,
+					ID:         event.ID,
+					DateEvent:  event.DateEvent,
+					NewTime:    event.TimeEvent,
+					DetectedAt: now,
+				})
+			}
+			known[event.ID] = entry
+			records = append(records, ledgerRecord{LedgerEntry: &entry})
+
+		case old.DateEvent != event.DateEvent || old.TimeEvent != event.TimeEvent:
+			audit := AuditEntry{
+				Kind:       AuditChanged,
+				ID:         event.ID,
+				DateEvent:  event.DateEvent,
+				OldTime:    old.TimeEvent,
+				NewTime:    event.TimeEvent,
+				DetectedAt: now,
+			}
+			if old.DateEvent != event.DateEvent {
+				audit.OldDate = old.DateEvent
+			}
+			audits = append(audits, audit)
+			records = append(records, ledgerRecord{LedgerEntry: &entry})
+		}
+	}
+
+	for _, entry := range ledger.Entries {
+		if earliest == "" || fetched[entry.ID] {
+			continue
+		}
+		if entry.DateEvent <= earliest || entry.DateEvent > latest || entry.DateEvent >= todayKey {
+			continue
+		}
+		audits = append(audits, AuditEntry{
+			Kind:       AuditRemoved,
+			ID:         entry.ID,
+			DateEvent:  entry.DateEvent,
+			OldTime:    entry.TimeEvent,
+			DetectedAt: now,
+		})
+		tombstone := entry
+		tombstone.Removed = true
+		tombstone.RecordedAt = now
+		records = append(records, ledgerRecord{LedgerEntry: &tombstone})
+	}
+
+	for i := range audits {
+		records = append(records, ledgerRecord{Audit: &audits[i]})
+	}
+
+	return records, audits
+}
+
+// WithLedgerHistory devolve events seguido das marcações conhecidas apenas
+// pelo livro local, sem gravar nada (usado ao iniciar a partir do cache).
+func WithLedgerHistory(events []ClockingEvent) ([]ClockingEvent, error) {
+	ledger, err := LoadLedger()
+	if err != nil {
+		return events, err
+	}
+	return withEntries(events, ledger.Entries), nil
+}
+
+func withEntries(events []ClockingEvent, entries []LedgerEntry) []ClockingEvent {
+	present := make(map[string]bool, len(events))
+	for _, event := range events {
+		present[event.ID] = true
+	}
+
+	merged := append([]ClockingEvent(nil), events...)
+	for _, entry := range entries {
+		if !present[entry.ID] {
+			merged = append(merged, entry.ClockingEvent())
+		}
+	}
+	return merged
+}
+
+// LoadLedger lê o livro local e reconstrói o estado atual. Para cada marcação
+// vale o registro mais recente; linhas ilegíveis são ignoradas.
+func LoadLedger() (Ledger, error) {
+	var ledger Ledger
+
+	data, err := os.ReadFile(GetLedgerFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ledger, nil
+		}
+		return ledger, fmt.Errorf("erro ao ler livro de marcações: %v", err)
+	}
+
+	key := deriveEncryptionKey()
+	index := make(map[string]int)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		record, err := decodeLedgerLine(scanner.Bytes(), key)
+		if err != nil {
+			log.Printf("Linha inválida no livro de marcações: %v", err)
+			continue
+		}
+
+		switch {
+		case record.Audit != nil:
+			ledger.Audits = append(ledger.Audits, *record.Audit)
+		case record.SyncedAt != nil:
+			if record.SyncedAt.After(ledger.LastSync) {
+				ledger.LastSync = *record.SyncedAt
+			}
+		case record.LedgerEntry != nil:
+			if i, ok := index[record.ID]; ok {
+				ledger.Entries[i] = *record.LedgerEntry
+				continue
+			}
+			index[record.ID] = len(ledger.Entries)
+			ledger.Entries = append(ledger.Entries, *record.LedgerEntry)
+		}
+	}
+
+	var entries []LedgerEntry
+	for _, entry := range ledger.Entries {
+		if !entry.Removed {
+			entries = append(entries, entry)
+		}
+	}
+	ledger.Entries = entries
+
+	return ledger, scanner.Err()
+}
+
+func DeleteLedger() error {
+	return removeFile(GetLedgerFilePath(), "livro de marcações")
+}
+
+func GetLedgerFilePath() string {
+	return homeFilePath(".clockwerk_ledger.enc")
+}
+
+// appendLedger grava uma linha criptografada por registro, sem reescrever as
+// existentes.
+func appendLedger(records []ledgerRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	key := deriveEncryptionKey()
+	var buf bytes.Buffer
+	for _, record := range records {
+		line, err := encodeLedgerLine(record, key)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(GetLedgerFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("Erro ao abrir livro de marcações: %v", err)
+		return fmt.Errorf("erro ao abrir livro de marcações: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		log.Printf("Erro ao gravar livro de marcações: %v", err)
+		return fmt.Errorf("erro ao gravar livro de marcações: %v", err)
+	}
+
+	return nil
+}
+
+func encodeLedgerLine(record ledgerRecord, key []byte) ([]byte, error) {
+	plaintext, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar registro do livro: %v", err)
+	}
+
+	ciphertext, iv, err := encrypt(plaintext, key)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criptografar: %v", err)
+	}
+
+	return json.Marshal(EncryptedData{
+		Data: base64.StdEncoding.EncodeToString(ciphertext),
+		IV:   base64.StdEncoding.EncodeToString(iv),
+	})
+}
+
+func decodeLedgerLine(line []byte, key []byte) (ledgerRecord, error) {
+	var record ledgerRecord
+
+	var encData EncryptedData
+	if err := json.Unmarshal(line, &encData); err != nil {
+		return record, err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encData.Data)
+	if err != nil {
+		return record, err
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(encData.IV)
+	if err != nil {
+		return record, err
+	}
+
+	plaintext, err := decrypt(ciphertext, key, iv)
+	if err != nil {
+		return record, err
+	}
+
+	err = json.Unmarshal(plaintext, &record)
+	return record, err
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ledgerEvent(id, date, clock string) ClockingEvent {
+	return ClockingEvent{ID: id, DateEvent: date, TimeEvent: clock}
+}
+
+func ledgerEntry(id, date, clock string) LedgerEntry {
+	return LedgerEntry{ID: id, DateEvent: date, TimeEvent: clock}
+}
+
+// auditSummary reduz um ajuste aos campos que o diff decide.
+type auditSummary struct {
+	Kind, ID, DateEvent, OldDate, OldTime, NewTime string
+}
+
+func TestDiffLedger(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local)
+	synced := time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		ledger  Ledger
+		events  []ClockingEvent
+		entries []string // IDs gravados como marcação, na ordem
+		removed []string // IDs gravados como removidos
+		audits  []auditSummary
+	}{
+		{
+			name: "primeira sincronização só registra",
+			events: []ClockingEvent{
+				ledgerEvent("a", "2024-03-13", "08:00:00"),
+				ledgerEvent("b", "2024-03-13", "12:00:00"),
+				ledgerEvent("c", "2024-03-14", "08:00:00"),
+				ledgerEvent("d", "2024-03-15", "08:00:00"),
+			},
+			entries: []string{"a", "b", "c", "d"},
+		},
+		{
+			name: "página que corta um dia não remove o início dele",
+			ledger: Ledger{
+				Entries: []LedgerEntry{
+					ledgerEntry("a", "2024-03-13", "08:00:00"),
+					ledgerEntry("b", "2024-03-13", "12:00:00"),
+					ledgerEntry("c", "2024-03-14", "08:00:00"),
+				},
+				LastSync: synced,
+			},
+			events: []ClockingEvent{
+				ledgerEvent("b", "2024-03-13", "12:00:00"),
+				ledgerEvent("c", "2024-03-14", "08:00:00"),
+			},
+		},
+		{
+			name: "marcação movida para outro dia",
+			ledger: Ledger{
+				Entries: []LedgerEntry{
+					ledgerEntry("a", "2024-03-12", "08:00:00"),
+					ledgerEntry("b", "2024-03-13", "18:00:00"),
+					ledgerEntry("c", "2024-03-14", "08:00:00"),
+				},
+				LastSync: synced,
+			},
+			events: []ClockingEvent{
+				ledgerEvent("a", "2024-03-12", "08:00:00"),
+				ledgerEvent("b", "2024-03-14", "07:55:00"),
+				ledgerEvent("c", "2024-03-14", "08:00:00"),
+			},
+			entries: []string{"b"},
+			audits: []auditSummary{
+				{Kind: AuditChanged, ID: "b", DateEvent: "2024-03-14", OldDate: "2024-03-13", OldTime: "18:00:00", NewTime: "07:55:00"},
+			},
+		},
+		{
+			name: "fora da janela (earliest, latest] nada é removido",
+			ledger: Ledger{
+				Entries: []LedgerEntry{
+					ledgerEntry("old", "2024-03-10", "08:00:00"),
+					ledgerEntry("edge", "2024-03-12", "08:00:00"),
+					ledgerEntry("x", "2024-03-12", "12:00:00"),
+					ledgerEntry("y", "2024-03-13", "08:00:00"),
+					ledgerEntry("after", "2024-03-14", "08:00:00"),
+					ledgerEntry("today", "2024-03-15", "08:00:00"),
+				},
+				LastSync: synced,
+			},
+			events: []ClockingEvent{
+				ledgerEvent("x", "2024-03-12", "12:00:00"),
+				ledgerEvent("y", "2024-03-13", "08:00:00"),
+			},
+		},
+		{
+			name: "marcação que sumiu dentro da janela é removida",
+			ledger: Ledger{
+				Entries: []LedgerEntry{
+					ledgerEntry("a", "2024-03-12", "08:00:00"),
+					ledgerEntry("b", "2024-03-13", "08:00:00"),
+					ledgerEntry("c", "2024-03-13", "12:00:00"),
+					ledgerEntry("d", "2024-03-14", "08:00:00"),
+				},
+				LastSync: synced,
+			},
+			events: []ClockingEvent{
+				ledgerEvent("a", "2024-03-12", "08:00:00"),
+				ledgerEvent("b", "2024-03-13", "08:00:00"),
+				ledgerEvent("d", "2024-03-14", "08:00:00"),
+			},
+			removed: []string{"c"},
+			audits: []auditSummary{
+				{Kind: AuditRemoved, ID: "c", DateEvent: "2024-03-13", OldTime: "12:00:00"},
+			},
+		},
+		{
+			name: "marcação nova em dia passado já sincronizado",
+			ledger: Ledger{
+				Entries: []LedgerEntry{
+					ledgerEntry("a", "2024-03-13", "08:00:00"),
+					ledgerEntry("b", "2024-03-14", "08:00:00"),
+				},
+				LastSync: synced,
+			},
+			events: []ClockingEvent{
+				ledgerEvent("a", "2024-03-13", "08:00:00"),
+				ledgerEvent("n", "2024-03-13", "12:00:00"),
+				ledgerEvent("b", "2024-03-14", "08:00:00"),
+				ledgerEvent("t", "2024-03-15", "08:00:00"),
+			},
+			entries: []string{"n", "t"},
+			audits: []auditSummary{
+				{Kind: AuditInserted, ID: "n", DateEvent: "2024-03-13", NewTime: "12:00:00"},
+			},
+		},
+		{
+			name: "marcação nova em dia ainda não sincronizado depois de encerrado",
+			ledger: Ledger{
+				Entries: []LedgerEntry{
+					ledgerEntry("a", "2024-03-14", "08:00:00"),
+				},
+				LastSync: time.Date(2024, 3, 14, 0, 0, 0, 0, time.Local),
+			},
+			events: []ClockingEvent{
+				ledgerEvent("a", "2024-03-14", "08:00:00"),
+				ledgerEvent("n", "2024-03-14", "12:00:00"),
+			},
+			entries: []string{"n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, audits := diffLedger(tt.ledger, tt.events, now)
+
+			var entries, removed []string
+			var recorded []auditSummary
+			for _, record := range records {
+				switch {
+				case record.LedgerEntry != nil && record.Removed:
+					removed = append(removed, record.ID)
+				case record.LedgerEntry != nil:
+					entries = append(entries, record.ID)
+				case record.Audit != nil:
+					recorded = append(recorded, summarizeAudit(*record.Audit))
+				}
+			}
+
+			var got []auditSummary
+			for _, audit := range audits {
+				got = append(got, summarizeAudit(audit))
+			}
+
+			if !reflect.DeepEqual(entries, tt.entries) {
+				t.Errorf("marcações gravadas = %v, esperado %v", entries, tt.entries)
+			}
+			if !reflect.DeepEqual(removed, tt.removed) {
+				t.Errorf("remoções gravadas = %v, esperado %v", removed, tt.removed)
+			}
+			if !reflect.DeepEqual(got, tt.audits) {
+				t.Errorf("ajustes = %+v, esperado %+v", got, tt.audits)
+			}
+			if !reflect.DeepEqual(recorded, tt.audits) {
+				t.Errorf("ajustes gravados = %+v, esperado %+v", recorded, tt.audits)
+			}
+		})
+	}
+}
+
+func summarizeAudit(a AuditEntry) auditSummary {
+	return auditSummary{
+		Kind:      a.Kind,
+		ID:        a.ID,
+		DateEvent: a.DateEvent,
+		OldDate:   a.OldDate,
+		OldTime:   a.OldTime,
+		NewTime:   a.NewTime,
+	}
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APILatencyBuckets são os limites (em segundos) do histograma de latência
+// das chamadas à Senior; o timeout das requisições é de 10s.
+var APILatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// APIOperationStats acumula as chamadas de uma operação (login, events,
+// punch) desde o início do processo.
+type APIOperationStats struct {
+	Operation string
+	// Outcomes conta as chamadas por resultado: "ok", "http_4xx", "http_5xx"
+	// ou "network".
+	Outcomes map[string]uint64
+	// Buckets[i] conta as chamadas com duração <= APILatencyBuckets[i].
+	Buckets     []uint64
+	Count       uint64
+	DurationSum time.Duration
+}
+
+var (
+	apiStatsMu sync.Mutex
+	apiStats   = map[string]*APIOperationStats{}
+)
+
+// observeAPICalls devolve o observador do cliente da Senior (ver
+// senior.Client.Observe): registra a latência e o resultado de cada chamada e
+// alimenta a estimativa da diferença de relógio (ver ClockSkew) só com as
+// respostas da plataforma em platformURL. O gateway de login é outro servidor,
+// possivelmente interno, e o relógio dele não vale para as marcações.
+func observeAPICalls(platformURL string) func(op string, resp *http.Response, err error, elapsed time.Duration) {
+	platformHost := ""
+	if u, err := url.Parse(platformURL); err == nil {
+		platformHost = u.Host
+	}
+	return func(op string, resp *http.Response, err error, elapsed time.Duration) {
+		if err == nil && resp.Request != nil && strings.EqualFold(resp.Request.URL.Host, platformHost) {
+			observeServerDate(resp, elapsed, time.Now())
+		}
+		observeAPICall(op, resp, err, elapsed)
+	}
+}
+
+// observeAPICall registra a latência e o resultado de uma chamada.
+func observeAPICall(op string, resp *http.Response, err error, elapsed time.Duration) {
+	outcome := "network"
+	if err == nil {
+		switch {
+		case resp.StatusCode >= 500:
+			outcome = "http_5xx"
+		case resp.StatusCode >= 400:
+			outcome = "http_4xx"
+		default:
+			outcome = "ok"
+		}
+	}
+	recordAPICall(op, outcome, elapsed)
+}
+
+func recordAPICall(op, outcome string, elapsed time.Duration) {
+	apiStatsMu.Lock()
+	defer apiStatsMu.Unlock()
+
+	stats, ok := apiStats[op]
+	if !ok {
+		stats = &APIOperationStats{
+			Operation: op,
+			Outcomes:  map[string]uint64{},
+			Buckets:   make([]uint64, len(APILatencyBuckets)),
+		}
+		apiStats[op] = stats
+	}
+
+	stats.Outcomes[outcome]++
+	stats.Count++
+	stats.DurationSum += elapsed
+	for i, bound := range APILatencyBuckets {
+		if elapsed.Seconds() <= bound {
+			stats.Buckets[i]++
+		}
+	}
+}
+
+// APIStats devolve uma cópia das estatísticas, ordenada por operação.
+func APIStats() []APIOperationStats {
+	apiStatsMu.Lock()
+	defer apiStatsMu.Unlock()
+
+	result := make([]APIOperationStats, 0, len(apiStats))
+	for _, stats := range apiStats {
+		cp := *stats
+		cp.Outcomes = make(map[string]uint64, len(stats.Outcomes))
+		for k, v := range stats.Outcomes {
+			cp.Outcomes[k] = v
+		}
+		cp.Buckets = append([]uint64(nil), stats.Buckets...)
+		result = append(result, cp)
+	}
+	slices.SortFunc(result, func(a, b APIOperationStats) int {
+		return strings.Compare(a.Operation, b.Operation)
+	})
+
+	return result
+}
@@ -7,6 +7,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 type UserCredentials struct {
@@ -14,6 +16,10 @@ type UserCredentials struct {
 	CPF      string `json:"cpf"`
 	Password string `json:"password"`
 	Token    string `json:"token"`
+	// TokenExpiresAt é a validade de Token, quando conhecida.
+	TokenExpiresAt time.Time `json:"tokenExpiresAt,omitempty"`
+	// Renewals guarda as últimas renovações automáticas do token.
+	Renewals []TokenRenewal `json:"renewals,omitempty"`
 }
 
 type EncryptedData struct {
@@ -22,10 +28,28 @@ type EncryptedData struct {
 }
 
 func SaveCredentials(creds UserCredentials) error {
-	jsonData, err := json.Marshal(creds)
+	creds.TokenExpiresAt, _ = TokenExpiry(creds.Token)
+	return writeEncryptedJSON(GetCredentialsFilePath(), creds, "credenciais")
+}
+
+func LoadCredentials() (UserCredentials, error) {
+	var creds UserCredentials
+	err := readEncryptedJSON(GetCredentialsFilePath(), &creds, "credenciais")
+	rememberTokenExpiry(creds.Token, creds.TokenExpiresAt)
+	if creds.Domain != "" {
+		SetTenantDomain(creds.Domain)
+	}
+	return creds, err
+}
+
+// writeEncryptedJSON serializa v, criptografa com a chave derivada da máquina
+// e grava em path com permissão apenas para o usuário. what descreve o
+// conteúdo nas mensagens de erro.
+func writeEncryptedJSON(path string, v any, what string) error {
+	jsonData, err := json.Marshal(v)
 	if err != nil {
-		log.Println("Erro ao serializar credenciais: %w", err)
-		return fmt.Errorf("erro ao serializar credenciais: %v", err)
+		log.Printf("Erro ao serializar %s: %v", what, err)
+		return fmt.Errorf("erro ao serializar %s: %v", what, err)
 	}
 
 	key := deriveEncryptionKey()
@@ -47,43 +71,42 @@ func SaveCredentials(creds UserCredentials) error {
 		return fmt.Errorf("erro ao serializar dados criptografados: %v", err)
 	}
 
-	err = os.WriteFile(GetCredentialsFilePath(), encJson, 0600) // Permissão apenas para o usuário
+	err = os.WriteFile(path, encJson, 0600) // Permissão apenas para o usuário
 	if err != nil {
-		log.Println("Erro ao salvar arquivo de credenciais: %w", err)
-		return fmt.Errorf("erro ao salvar arquivo de credenciais: %v", err)
+		log.Printf("Erro ao salvar arquivo de %s: %v", what, err)
+		return fmt.Errorf("erro ao salvar arquivo de %s: %v", what, err)
 	}
 
 	return nil
 }
 
-func LoadCredentials() (UserCredentials, error) {
-	var creds UserCredentials
-
-	filePath := GetCredentialsFilePath()
-	data, err := os.ReadFile(filePath)
+// readEncryptedJSON lê e descriptografa path em v. Arquivo inexistente não é
+// erro: v permanece com o valor zero.
+func readEncryptedJSON(path string, v any, what string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return creds, nil
+			return nil
 		}
-		return creds, fmt.Errorf("erro ao ler arquivo de credenciais: %v", err)
+		return fmt.Errorf("erro ao ler arquivo de %s: %v", what, err)
 	}
 
 	var encData EncryptedData
 	if err := json.Unmarshal(data, &encData); err != nil {
 		log.Println("Erro ao desserializar dados criptografados: %w", err)
-		return creds, fmt.Errorf("erro ao desserializar dados criptografados: %v", err)
+		return fmt.Errorf("erro ao desserializar dados criptografados: %v", err)
 	}
 
 	ciphertext, err := base64.StdEncoding.DecodeString(encData.Data)
 	if err != nil {
 		log.Println("Erro ao decodificar ciphertext: %w", err)
-		return creds, fmt.Errorf("erro ao decodificar ciphertext: %v", err)
+		return fmt.Errorf("erro ao decodificar ciphertext: %v", err)
 	}
 
 	iv, err := base64.StdEncoding.DecodeString(encData.IV)
 	if err != nil {
 		log.Println("Erro ao decodificar IV: %w", err)
-		return creds, fmt.Errorf("erro ao decodificar IV: %v", err)
+		return fmt.Errorf("erro ao decodificar IV: %v", err)
 	}
 
 	key := deriveEncryptionKey()
@@ -91,32 +114,41 @@ func LoadCredentials() (UserCredentials, error) {
 	plaintext, err := decrypt(ciphertext, key, iv)
 	if err != nil {
 		log.Println("Erro ao descriptografar: %w", err)
-		return creds, fmt.Errorf("erro ao descriptografar: %v", err)
+		return fmt.Errorf("erro ao descriptografar: %v", err)
 	}
 
-	if err := json.Unmarshal(plaintext, &creds); err != nil {
-		log.Println("Erro ao desserializar credenciais: %w", err)
-		return creds, fmt.Errorf("erro ao desserializar credenciais: %v", err)
+	if err := json.Unmarshal(plaintext, v); err != nil {
+		log.Printf("Erro ao desserializar %s: %v", what, err)
+		return fmt.Errorf("erro ao desserializar %s: %v", what, err)
 	}
 
-	return creds, nil
+	return nil
 }
 
 func DeleteCredentials() error {
-	filePath := GetCredentialsFilePath()
-	err := os.Remove(filePath)
+	return removeFile(GetCredentialsFilePath(), "credenciais")
+}
+
+func removeFile(path string, what string) error {
+	err := os.Remove(path)
 	if err != nil && !os.IsNotExist(err) {
-		log.Println("Erro ao remover arquivo de credenciais: %w", err)
-		return fmt.Errorf("erro ao remover arquivo de credenciais: %v", err)
+		log.Printf("Erro ao remover arquivo de %s: %v", what, err)
+		return fmt.Errorf("erro ao remover arquivo de %s: %v", what, err)
 	}
 	return nil
 }
 
 func GetCredentialsFilePath() string {
+	return homeFilePath(".clockwerk_credentials.enc")
+}
+
+// homeFilePath resolve name dentro do diretório do usuário; sem HOME, usa o
+// diretório corrente (sem o ponto inicial).
+func homeFilePath(name string) string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		log.Println("Erro ao obter diretório do usuário: %w", err)
-		return "clockwerk_credentials.enc"
+		return strings.TrimPrefix(name, ".")
 	}
-	return filepath.Join(homeDir, ".clockwerk_credentials.enc")
+	return filepath.Join(homeDir, name)
 }
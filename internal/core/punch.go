@@ -0,0 +1,90 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultPunchCooldown é o intervalo mínimo entre duas marcações quando
+// "punchCooldown" não está na configuração.
+const DefaultPunchCooldown = 2 * time.Minute
+
+// Significado de uma marcação pela posição no dia (ver PunchIntent).
+const (
+	IntentEntry      = "Entrada"
+	IntentBreakStart = "Saída para intervalo"
+	IntentBreakEnd   = "Retorno do intervalo"
+	IntentExit       = "Saída"
+	IntentExtra      = "Marcação extra"
+)
+
+// PunchIntent devolve o significado da marcação de índice n (a partir de 0)
+// no dia: as posições pares abrem um bloco de trabalho e as ímpares o
+// fecham. Com o expediente interpretável, a última marcação prevista é a
+// Saída e as demais saídas são para intervalo; sem ele, toda saída é Saída.
+func PunchIntent(timeTable string, n int) string {
+	exp, ok := ParseTimeTable(timeTable)
+	switch {
+	case ok && n >= len(exp):
+		return IntentExtra
+	case n == 0:
+		return IntentEntry
+	case n%2 == 0:
+		return IntentBreakEnd
+	case ok && n < len(exp)-1:
+		return IntentBreakStart
+	default:
+		return IntentExit
+	}
+}
+
+// PunchCheck é o resultado de CheckPunch. Reasons explica cada motivo que
+// bloqueia a marcação; vazio, ela pode seguir sem confirmação extra.
+type PunchCheck struct {
+	Reasons []string
+}
+
+// Blocked informa se a marcação só deve seguir com uma confirmação explícita.
+func (c PunchCheck) Blocked() bool {
+	return len(c.Reasons) > 0
+}
+
+// CheckPunch confere uma nova marcação em now contra as marcações de hoje
+// (punches, em ordem crescente) e o expediente. Bloqueia quando a última
+// marcação foi há menos de cooldown (clique duplo, comando repetido) e quando
+// a marcação passaria do número previsto pelo expediente.
+func CheckPunch(timeTable string, punches []time.Time, now time.Time, cooldown time.Duration) PunchCheck {
+	var check PunchCheck
+	n := len(punches)
+
+	if n > 0 && cooldown > 0 {
+		last := punches[n-1]
+		if since := now.Sub(last); since < cooldown {
+			check.Reasons = append(check.Reasons, fmt.Sprintf(
+				"a última marcação foi às %s, há %s; o intervalo mínimo é de %s",
+				last.Format("15:04:05"), formatShortDuration(max(since, 0)), formatShortDuration(cooldown),
+			))
+		}
+	}
+
+	if exp, ok := ParseTimeTable(timeTable); ok && n >= len(exp) {
+		check.Reasons = append(check.Reasons, fmt.Sprintf(
+			"esta seria a %dª marcação de hoje, mas o expediente (%s) prevê %d",
+			n+1, timeTable, len(exp),
+		))
+	}
+
+	return check
+}
+
+// formatShortDuration formata durações curtas: "40s", "2m", "1m30s".
+func formatShortDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d/time.Minute), int(d%time.Minute/time.Second))
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckPunch(t *testing.T) {
+	const timeTable = "08:00-12:00-13:00-17:00"
+	at := func(clock string) time.Time {
+		at, err := time.Parse("2006-01-02 15:04:05", "2024-03-15 "+clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+	punchesAt := func(clocks ...string) []time.Time {
+		punches := make([]time.Time, len(clocks))
+		for i, clock := range clocks {
+			punches[i] = at(clock)
+		}
+		return punches
+	}
+
+	tests := []struct {
+		name      string
+		timeTable string
+		punches   []time.Time
+		now       string
+		cooldown  time.Duration
+		want      []string
+	}{
+		{name: "primeira do dia", timeTable: timeTable, now: "07:58:00", cooldown: 2 * time.Minute},
+		{
+			name:      "logo depois da anterior",
+			timeTable: timeTable,
+			punches:   punchesAt("08:00:00"),
+			now:       "08:00:40",
+			cooldown:  2 * time.Minute,
+			want:      []string{"a última marcação foi às 08:00:00, há 40s; o intervalo mínimo é de 2m"},
+		},
+		{
+			name:      "um segundo antes do fim do intervalo mínimo",
+			timeTable: timeTable,
+			punches:   punchesAt("08:00:00"),
+			now:       "08:01:59",
+			cooldown:  2 * time.Minute,
+			want:      []string{"a última marcação foi às 08:00:00, há 1m59s; o intervalo mínimo é de 2m"},
+		},
+		{name: "exatamente no intervalo mínimo", timeTable: timeTable, punches: punchesAt("08:00:00"), now: "08:02:00", cooldown: 2 * time.Minute},
+		{name: "depois do intervalo mínimo", timeTable: timeTable, punches: punchesAt("08:00:00"), now: "08:02:01", cooldown: 2 * time.Minute},
+		{
+			name:      "intervalo mínimo fracionado",
+			timeTable: timeTable,
+			punches:   punchesAt("08:00:00"),
+			now:       "08:01:00",
+			cooldown:  90 * time.Second,
+			want:      []string{"a última marcação foi às 08:00:00, há 1m; o intervalo mínimo é de 1m30s"},
+		},
+		{name: "intervalo mínimo desligado", timeTable: timeTable, punches: punchesAt("08:00:00"), now: "08:00:01"},
+		{
+			name:      "relógio local atrás da última marcação",
+			timeTable: timeTable,
+			punches:   punchesAt("08:00:00"),
+			now:       "07:59:30",
+			cooldown:  2 * time.Minute,
+			want:      []string{"a última marcação foi às 08:00:00, há 0s; o intervalo mínimo é de 2m"},
+		},
+		{name: "última prevista pelo expediente", timeTable: timeTable, punches: punchesAt("08:00:00", "12:00:00", "13:00:00"), now: "17:00:00", cooldown: 2 * time.Minute},
+		{
+			name:      "além do expediente",
+			timeTable: timeTable,
+			punches:   punchesAt("08:00:00", "12:00:00", "13:00:00", "17:00:00"),
+			now:       "18:00:00",
+			cooldown:  2 * time.Minute,
+			want:      []string{"esta seria a 5ª marcação de hoje, mas o expediente (08:00-12:00-13:00-17:00) prevê 4"},
+		},
+		{
+			name:      "além do expediente e logo depois da anterior",
+			timeTable: timeTable,
+			punches:   punchesAt("08:00:00", "12:00:00", "13:00:00", "17:00:00"),
+			now:       "17:00:30",
+			cooldown:  2 * time.Minute,
+			want: []string{
+				"a última marcação foi às 17:00:00, há 30s; o intervalo mínimo é de 2m",
+				"esta seria a 5ª marcação de hoje, mas o expediente (08:00-12:00-13:00-17:00) prevê 4",
+			},
+		},
+		{name: "expediente ininterpretável não limita", timeTable: "flexível", punches: punchesAt("08:00:00", "12:00:00", "13:00:00", "17:00:00"), now: "18:00:00", cooldown: 2 * time.Minute},
+		{
+			name:      "expediente de um bloco",
+			timeTable: "08:00-14:00",
+			punches:   punchesAt("08:00:00", "14:00:00"),
+			now:       "15:00:00",
+			want:      []string{"esta seria a 3ª marcação de hoje, mas o expediente (08:00-14:00) prevê 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := CheckPunch(tt.timeTable, tt.punches, at(tt.now), tt.cooldown)
+			if !reflect.DeepEqual(check.Reasons, tt.want) {
+				t.Errorf("Reasons = %q, esperado %q", check.Reasons, tt.want)
+			}
+			if check.Blocked() != (len(tt.want) > 0) {
+				t.Errorf("Blocked = %v com %d motivos", check.Blocked(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestPunchIntent(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeTable string
+		want      []string // significado de cada posição, a partir de 0
+	}{
+		{
+			name:      "dois blocos",
+			timeTable: "08:00-12:00-13:00-17:00",
+			want:      []string{IntentEntry, IntentBreakStart, IntentBreakEnd, IntentExit, IntentExtra, IntentExtra},
+		},
+		{
+			name:      "três blocos",
+			timeTable: "08:00-10:00-10:15-12:00-13:00-17:00",
+			want:      []string{IntentEntry, IntentBreakStart, IntentBreakEnd, IntentBreakStart, IntentBreakEnd, IntentExit, IntentExtra},
+		},
+		{
+			name:      "um bloco",
+			timeTable: "08:00-14:00",
+			want:      []string{IntentEntry, IntentExit, IntentExtra},
+		},
+		{
+			name:      "sem expediente",
+			timeTable: "",
+			want:      []string{IntentEntry, IntentExit, IntentBreakEnd, IntentExit, IntentBreakEnd, IntentExit},
+		},
+		{
+			name:      "expediente ímpar",
+			timeTable: "08:00-12:00-13:00",
+			want:      []string{IntentEntry, IntentExit, IntentBreakEnd, IntentExit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, len(tt.want))
+			for n := range got {
+				got[n] = PunchIntent(tt.timeTable, n)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PunchIntent = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+const (
+	// RetryBaseDelay é a espera antes da primeira nova tentativa; dobra a
+	// cada falha seguida até RetryMaxDelay.
+	RetryBaseDelay = 5 * time.Second
+	RetryMaxDelay  = 5 * time.Minute
+	// ProbeInterval é a frequência da checagem de conectividade enquanto uma
+	// leitura aguarda nova tentativa por falha de rede.
+	ProbeInterval = 5 * time.Second
+)
+
+// Retryable informa se uma leitura que falhou com err pode ser repetida
+// automaticamente: falhas de rede ou do proxy, limite de requisições e erros 5xx da
+// Senior. Credenciais, token ou autenticação no proxy recusados dependem do
+// usuário ou de um novo login, e marcações de ponto nunca devem passar por
+// aqui.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, senior.ErrProxyAuth) {
+		return false
+	}
+
+	var serverErr *senior.ErrServer
+	if errors.As(err, &serverErr) {
+		return serverErr.Status >= 500
+	}
+	return errors.Is(err, senior.ErrNetwork) || errors.Is(err, senior.ErrProxy) || errors.Is(err, senior.ErrRateLimited)
+}
+
+// Backoff devolve a espera antes da nova tentativa de número attempt (a
+// partir de 1). A espera cresce exponencialmente e metade dela é sorteada,
+// para que TUI, daemon e outras instâncias não voltem todos ao mesmo tempo.
+func Backoff(attempt int) time.Duration {
+	d := RetryMaxDelay
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt <= 16 {
+		d = min(RetryBaseDelay<<(attempt-1), RetryMaxDelay)
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// Online testa se a plataforma da Senior aceita conexões TCP, ou o proxy,
+// quando as chamadas passam por um. É usado para antecipar a nova tentativa
+// quando a rede volta.
+func Online(ctx context.Context) bool {
+	u, err := url.Parse(SeniorClient().Config().PlatformURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if transport, ok := seniorTransport.(*http.Transport); ok && transport.Proxy != nil {
+		if proxyURL, err := transport.Proxy(&http.Request{URL: u}); err == nil && proxyURL != nil {
+			u = proxyURL
+		}
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		port := "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
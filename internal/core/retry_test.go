@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "sem erro"},
+		{name: "cancelado", err: context.Canceled},
+		{name: "rede", err: fmt.Errorf("%w: connection refused", senior.ErrNetwork), want: true},
+		{name: "proxy inacessível", err: fmt.Errorf("%w: proxyconnect tcp: connection refused", senior.ErrProxy), want: true},
+		{name: "proxy exige autenticação", err: fmt.Errorf("%w: Proxy Authentication Required", senior.ErrProxyAuth)},
+		{name: "limite de requisições", err: senior.ErrRateLimited, want: true},
+		{name: "erro 503", err: &senior.ErrServer{Status: 503}, want: true},
+		{name: "erro 400", err: &senior.ErrServer{Status: 400}},
+		{name: "credenciais recusadas", err: senior.ErrUnauthorized},
+		{name: "token expirado", err: senior.ErrTokenExpired},
+		{name: "TLS", err: fmt.Errorf("%w: x509", senior.ErrTLS)},
+		{name: "sem classificação", err: errors.New("outro")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Retryable(tt.err); got != tt.want {
+				t.Errorf("Retryable = %v, esperado %v", got, tt.want)
+			}
+			// O erro repassado pelo daemon deve ser classificado igual.
+			if tt.err != nil {
+				if got := Retryable(IPCErrorResponse(tt.err).err()); got != tt.want {
+					t.Errorf("Retryable via daemon = %v, esperado %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -1,310 +1,151 @@
 package core
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
+	"context"
 	"log"
 	"net/http"
-	"time"
-)
-
-type loginRequest struct {
-	User     string `json:"user"`
-	Password string `json:"password"`
-}
-
-type loginResponse struct {
-	Token string `json:"token"`
-}
-
-type errorResponse struct {
-	Message string   `json:"message"`
-	Errors  []string `json:"errors"`
-}
-
-type requestFilter struct {
-	ActivePlatformUser bool     `json:"activePlatformUser"`
-	PageInfo           pageInfo `json:"pageInfo"`
-	NameSearch         string   `json:"nameSearch"`
-	Sort               sort     `json:"sort"`
-}
+	"strings"
+	"sync"
 
-type pageInfo struct {
-	Page     int    `json:"page"`
-	PageSize string `json:"pageSize"`
-}
-
-type sort struct {
-	Field interface{} `json:"field"`
-	Order string      `json:"order"`
-}
-
-type clockingEventRequest struct {
-	Filter requestFilter `json:"filter"`
-}
-
-type clockingEventResponse struct {
-	Result []clockingEvent `json:"result"`
-}
-
-type clockingEventImported struct {
-	DateEvent string `json:"dateEvent"`
-	TimeEvent string `json:"timeEvent"`
-}
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
 
-type clockingResult struct {
-	EventImported clockingEventImported `json:"clockingEventImported"`
-}
+// Tipos da API da Senior usados pelo restante do Clockwerk.
+type (
+	ClockingEvent     = senior.ClockingEvent
+	ClockingRequest   = senior.ClockingRequest
+	ClockingInfo      = senior.ClockingInfo
+	ClockingCompany   = senior.ClockingCompany
+	ClockingEmployee  = senior.ClockingEmployee
+	ClockingSignature = senior.ClockingSignature
+)
 
-type postClockingEventResponse struct {
-	Result clockingResult `json:"clockingResult"`
-}
+var (
+	seniorClientOnce sync.Once
+	seniorClient     *senior.Client
+	seniorTransport  http.RoundTripper
 
-type ClockingCompany struct {
-	ID         string `json:"id"`
-	ArpID      string `json:"arpId"`
-	Identifier string `json:"identifier"`
-	Caepf      string `json:"caepf"`
-	CnoNumber  string `json:"cnoNumber"`
-}
+	tenantMu     sync.Mutex
+	tenantDomain string
+)
 
-type ClockingEmployee struct {
-	ID    string `json:"id"`
-	ArpID string `json:"arpId"`
-	Cpf   string `json:"cpf"`
-	Pis   string `json:"pis"`
-}
+// SeniorClient devolve o cliente compartilhado da Senior, criado na primeira
+// chamada a partir do arquivo de configuração. Uma configuração inválida é
+// registrada no log e os padrões são usados.
+func SeniorClient() *senior.Client {
+	seniorClientOnce.Do(func() {
+		config, err := LoadConfig()
+		if err != nil {
+			log.Printf("Erro ao carregar configuração: %v", err)
+		}
 
-type ClockingSignature struct {
-	SignatureVersion int    `json:"signatureVersion"`
-	Signature        string `json:"signature"`
-}
+		httpClient := NewHTTPClient(config)
+		seniorTransport = httpClient.Transport
+
+		seniorClient = senior.NewClient(senior.Config{
+			GatewayURL:      config.GatewayURL,
+			UseGateway:      config.UseGateway,
+			GatewayFallback: config.GatewayFallback,
+			PlatformURL:     config.PlatformURL,
+			Tenant:          config.Tenant,
+			UserAgent:       "clockwerk/" + Version,
+			AppVersion:      Version,
+		}, httpClient)
+		seniorClient.Observe = observeAPICalls(seniorClient.Config().PlatformURL)
+	})
+	return seniorClient
+}
+
+// NewHTTPClient monta o cliente HTTP das chamadas à Senior com o timeout, o
+// proxy e o TLS de config. Uma configuração de rede inválida não cai nos
+// padrões: toda requisição falha com o erro, que aparece para o usuário.
+func NewHTTPClient(config Config) *http.Client {
+	httpClient := &http.Client{Timeout: senior.DefaultTimeout}
+	if timeout := config.RequestTimeout(); timeout > 0 {
+		httpClient.Timeout = timeout
+	}
 
-type ClockingInfo struct {
-	Company    ClockingCompany   `json:"company"`
-	Employee   ClockingEmployee  `json:"employee"`
-	AppVersion string            `json:"appVersion"`
-	TimeZone   string            `json:"timeZone"`
-	Signature  ClockingSignature `json:"signature"`
-	Use        string            `json:"use"`
+	transport, err := senior.NewTransport(config.TransportConfig())
+	if err != nil {
+		log.Printf("Erro na configuração de rede: %v", err)
+		httpClient.Transport = failedTransport{err: err}
+	} else {
+		httpClient.Transport = transport
+	}
+	return httpClient
 }
 
-type ClockingRequest struct {
-	ClockingInfo ClockingInfo `json:"clockingInfo"`
-}
+// failedTransport recusa toda requisição com o erro da configuração de rede.
+type failedTransport struct{ err error }
 
-type clockingEvent struct {
-	ID               string   `json:"id"`
-	DateEvent        string   `json:"dateEvent"`
-	TimeEvent        string   `json:"timeEvent"`
-	Cnpj             string   `json:"cnpj"`
-	Caepf            string   `json:"caepf"`
-	CnoNumber        string   `json:"cnoNumber"`
-	AppVersion       string   `json:"appVersion"`
-	TimeZone         string   `json:"timeZone"`
-	Signature        string   `json:"signature"`
-	SignatureVersion int      `json:"signatureVersion"`
-	Employee         employee `json:"employee"`
-	Platform         string   `json:"platform"`
-	Use              int      `json:"use"`
+func (t failedTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
 }
 
-type employee struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	Pis       string  `json:"pis"`
-	Shift     string  `json:"shift"`
-	Timetable string  `json:"timeTable"`
-	Company   company `json:"company"`
-	ArpID     string  `json:"arpId"`
-	CpfNumber string  `json:"cpfNumber"`
+// SetTenantDomain define o domínio da empresa do usuário, de onde vem o
+// tenant das rotas da plataforma (a menos que "tenant" esteja fixado na
+// configuração).
+func SetTenantDomain(domain string) {
+	tenantMu.Lock()
+	defer tenantMu.Unlock()
+	tenantDomain = domain
 }
 
-type company struct {
-	Cnpj  string `json:"cnpj"`
-	Name  string `json:"name"`
-	ID    string `json:"id"`
-	ArpID string `json:"arpId"`
+// tenantClient devolve o cliente compartilhado apontado para o tenant do
+// usuário atual.
+func tenantClient() *senior.Client {
+	tenantMu.Lock()
+	domain := tenantDomain
+	tenantMu.Unlock()
+	return SeniorClient().WithTenant(senior.TenantFromDomain(domain))
 }
 
-func GatewayLogin(user, password string) (string, error) {
-	requestBody := loginRequest{
-		User:     user,
-		Password: password,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		log.Println("Erro ao serializar dados: %w", err)
-		return "", fmt.Errorf("erro ao serializar dados: %w", err)
-	}
-
-	req, err := http.NewRequest(
-		"POST",
-		"https://snr-getaway.fly.dev/senior/login",
-		bytes.NewBuffer(jsonBody),
-	)
-	if err != nil {
-		log.Println("Erro ao criar requisição: %w", err)
-		return "", fmt.Errorf("erro ao criar requisição: %w", err)
+// Login autentica na Senior e devolve o token, registrando a validade
+// informada pela plataforma para a renovação antecipada (ver TokenExpiry).
+func Login(ctx context.Context, user, password string) (string, error) {
+	if _, domain, ok := strings.Cut(user, "@"); ok {
+		SetTenantDomain(domain)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	token, err := SeniorClient().Login(ctx, user, password)
 	if err != nil {
-		log.Println("Erro ao executar requisição: %w", err)
-		return "", fmt.Errorf("erro ao executar requisição: %w", err)
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var successResponse loginResponse
-		if err := json.NewDecoder(resp.Body).Decode(&successResponse); err != nil {
-			log.Println("Erro ao decodificar resposta: %w", err)
-			return "", fmt.Errorf("erro ao decodificar resposta: %w", err)
-		}
-		return successResponse.Token, nil
-
-	case http.StatusUnauthorized:
-		fallthrough
-	case http.StatusUnprocessableEntity:
-		var errorResponse errorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			log.Println("Erro ao decodificar resposta de erro: %w", err)
-			return "", fmt.Errorf("erro ao decodificar resposta de erro: %w", err)
-		}
-		return "", fmt.Errorf("%s", errorResponse.Message)
-
-	default:
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("resposta inesperada (status %d): %s", resp.StatusCode, string(body))
+		log.Printf("Erro no login: %v", err)
+		return "", err
 	}
+	rememberTokenExpiry(token.AccessToken, token.ExpiresAt)
+	return token.AccessToken, nil
 }
 
-func GetClockingEvents(token string) ([]clockingEvent, error) {
-	requestBody := clockingEventRequest{
-		Filter: requestFilter{
-			ActivePlatformUser: true,
-			PageInfo: pageInfo{
-				Page: 0,
-				// Maior que o padrão para cobrir o mês na visão mensal do Histórico
-				// (a query não tem filtro por data).
-				PageSize: "200",
-			},
-			NameSearch: "",
-			Sort: sort{
-				Field: nil,
-				Order: "ASC",
-			},
-		},
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		log.Println("Erro ao serializar dados: %w", err)
-		return nil, fmt.Errorf("erro ao serializar dados: %w", err)
-	}
-
-	req, err := http.NewRequest(
-		"POST",
-		"https://platform.senior.com.br/t/senior.com.br/bridge/1.0/rest/hcm/pontomobile/queries/clockingEventByActiveUserQuery",
-		bytes.NewBuffer(jsonBody),
-	)
-	if err != nil {
-		log.Println("Erro ao criar requisição: %w", err)
-		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
-	}
-
-	req.Header.Set("content-type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+// GetClockingEvents busca a primeira página de eventos (os mais recentes).
+func GetClockingEvents(ctx context.Context, token string) ([]ClockingEvent, error) {
+	events, err := tenantClient().ClockingEvents(ctx, token, 0)
 	if err != nil {
-		log.Println("Erro ao executar requisição: %w", err)
-		return nil, fmt.Errorf("erro ao executar requisição: %w", err)
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var response clockingEventResponse
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-			log.Println("Erro ao decodificar resposta: %w", err)
-			return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
-		}
-		return response.Result, nil
-
-	case http.StatusUnauthorized:
-		var errorResponse errorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			log.Println("Erro ao decodificar resposta de erro: %w", err)
-			return nil, fmt.Errorf("token expirado ou inválido: %w", err)
-		}
-		return nil, fmt.Errorf("autorização falhou: %s", errorResponse.Message)
-
-	default:
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("erro inesperado (status %d): %s", resp.StatusCode, string(body))
+		log.Printf("Erro ao buscar eventos: %v", err)
 	}
+	return events, err
 }
 
-func PostClockingEvent(token string, body ClockingRequest) (postClockingEventResponse, error) {
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		log.Println("Erro ao serializar dados: %w", err)
-		return postClockingEventResponse{}, fmt.Errorf("erro ao serializar dados: %w", err)
-	}
-
-	req, err := http.NewRequest(
-		"POST",
-		"https://platform.senior.com.br/t/senior.com.br/bridge/1.0/rest/hcm/pontomobile_clocking_event/actions/clockingEventImportByBrowser",
-		bytes.NewBuffer(jsonBody),
-	)
+// QueryClockingEvents busca os eventos de from a to, paginando a query da
+// Senior até cobrir o período.
+func QueryClockingEvents(ctx context.Context, token, from, to string) ([]ClockingEvent, error) {
+	events, err := tenantClient().QueryClockingEvents(ctx, token, from, to)
 	if err != nil {
-		log.Println("Erro ao criar requisição: %w", err)
-		return postClockingEventResponse{}, fmt.Errorf("erro ao criar requisição: %w", err)
+		log.Printf("Erro ao buscar eventos de %s a %s: %v", from, to, err)
 	}
+	return events, err
+}
 
-	req.Header.Set("content-type", "application/json")
-	req.Header.Set("authorization", "Bearer "+token)
+// ValidateToken confere um token obtido fora do Clockwerk (SSO) com uma
+// consulta de eventos.
+func ValidateToken(ctx context.Context, token string) error {
+	_, err := GetClockingEvents(ctx, token)
+	return err
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+func PostClockingEvent(ctx context.Context, token string, body ClockingRequest) (senior.PostClockingEventResponse, error) {
+	resp, err := tenantClient().PostClockingEvent(ctx, token, body)
 	if err != nil {
-		log.Println("Erro ao executar requisição: %w", err)
-		return postClockingEventResponse{}, fmt.Errorf("erro ao executar requisição: %w", err)
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var result postClockingEventResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			log.Println("Erro ao decodificar resposta: %w", err)
-			return postClockingEventResponse{}, fmt.Errorf("erro ao decodificar resposta: %w", err)
-		}
-		return result, nil
-
-	case http.StatusUnauthorized:
-		var errorResponse errorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			log.Println("Erro de autenticação: %w", err)
-			return postClockingEventResponse{}, fmt.Errorf("erro de autenticação: %w", err)
-		}
-		return postClockingEventResponse{}, fmt.Errorf("não autorizado: %s", errorResponse.Message)
-
-	default:
-		body, _ := io.ReadAll(resp.Body)
-		return postClockingEventResponse{}, fmt.Errorf(
-			"erro inesperado (status %d): %s",
-			resp.StatusCode,
-			string(body),
-		)
+		log.Printf("Erro ao registrar marcação: %v", err)
 	}
+	return resp, err
 }
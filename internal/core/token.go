@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenRenewMargin é a antecedência com que o token é renovado antes de
+// expirar. É maior que o ciclo de refresh de 10 min, então uma sessão aberta
+// renova o token antes de qualquer chamada recebê-lo vencido.
+const TokenRenewMargin = 15 * time.Minute
+
+// maxTokenRenewals limita o histórico de renovações salvo nas credenciais.
+const maxTokenRenewals = 20
+
+// Motivos de uma renovação de token.
+const (
+	RenewalProactive = "proactive" // perto de expirar
+	RenewalExpired   = "expired"   // recusado pela Senior
+)
+
+// TokenRenewal registra uma renovação do token com as credenciais salvas.
+type TokenRenewal struct {
+	At        time.Time `json:"at"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// tokenExpiries guarda a validade informada no login para tokens opacos (o
+// login da plataforma não devolve JWT). É alimentado por Login e pelas
+// credenciais salvas.
+var tokenExpiries sync.Map
+
+func rememberTokenExpiry(token string, expiry time.Time) {
+	if token != "" && !expiry.IsZero() {
+		tokenExpiries.Store(token, expiry)
+	}
+}
+
+// TokenExpiry devolve a expiração do token: a claim exp, se for um JWT, ou a
+// validade informada no login. ok é falso quando ela é desconhecida; nesse
+// caso o token só é renovado quando a Senior o recusa.
+func TokenExpiry(token string) (time.Time, bool) {
+	if expiry, ok := tokenExpiries.Load(token); ok {
+		return expiry.(time.Time), true
+	}
+	return jwtExpiry(token)
+}
+
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(exp), 0), true
+}
+
+// NormalizeBearerToken limpa um token colado pelo usuário: remove espaços,
+// quebras de linha e o prefixo "Bearer " copiado junto do cabeçalho.
+func NormalizeBearerToken(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 7 && strings.EqualFold(s[:7], "bearer ") {
+		s = strings.TrimSpace(s[7:])
+	}
+	return s
+}
+
+// TokenNeedsRenewal informa se o token expira em menos de TokenRenewMargin.
+func TokenNeedsRenewal(token string, now time.Time) bool {
+	expiry, ok := TokenExpiry(token)
+	return ok && expiry.Sub(now) < TokenRenewMargin
+}
+
+// SetRenewedToken troca o token e registra a renovação no histórico.
+func (c *UserCredentials) SetRenewedToken(token, reason string, now time.Time) {
+	c.Token = token
+
+	renewal := TokenRenewal{At: now, Reason: reason}
+	if expiry, ok := TokenExpiry(token); ok {
+		renewal.ExpiresAt = expiry
+	}
+	c.Renewals = append(c.Renewals, renewal)
+	if len(c.Renewals) > maxTokenRenewals {
+		c.Renewals = c.Renewals[len(c.Renewals)-maxTokenRenewals:]
+	}
+}
+
+// RenewToken refaz o login com a senha salva em creds e registra o novo
+// token. Não persiste as credenciais.
+func RenewToken(ctx context.Context, creds *UserCredentials, reason string) error {
+	if creds.Password == "" {
+		return fmt.Errorf("sem senha salva para renovar o token")
+	}
+
+	token, err := Login(ctx, fmt.Sprintf("%s@%s", creds.CPF, creds.Domain), creds.Password)
+	if err != nil {
+		return err
+	}
+	creds.SetRenewedToken(token, reason, time.Now())
+	return nil
+}
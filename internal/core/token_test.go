@@ -0,0 +1,71 @@
+package core
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// testJWT monta um JWT sem assinatura válida com o payload informado.
+func testJWT(payload string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode([]byte(payload)) + ".assinatura"
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	opaqueExpiry := time.Date(2024, 3, 15, 20, 0, 0, 0, time.UTC)
+	rememberTokenExpiry("token-opaco-lembrado", opaqueExpiry)
+
+	tests := []struct {
+		name  string
+		token string
+		want  time.Time
+		ok    bool
+	}{
+		{name: "JWT com exp", token: testJWT(`{"sub":"1","exp":1710527400}`), want: exp, ok: true},
+		{name: "exp decimal", token: testJWT(`{"exp":1710527400.0}`), want: exp, ok: true},
+		{name: "payload com padding", token: "e30." + base64.URLEncoding.EncodeToString([]byte(`{"exp": 1710527400}`)) + ".x", want: exp, ok: true},
+		{name: "JWT sem exp", token: testJWT(`{"sub":"1"}`)},
+		{name: "exp zerado", token: testJWT(`{"exp":0}`)},
+		{name: "exp como texto", token: testJWT(`{"exp":"amanhã"}`)},
+		{name: "payload que não é JSON", token: testJWT(`não é json`)},
+		{name: "payload fora de base64", token: "a.%%%.c"},
+		{name: "partes demais", token: testJWT(`{"exp":1710527400}`) + ".d"},
+		{name: "token opaco", token: "3f2c9a7e-opaco"},
+		{name: "token vazio"},
+		{name: "token opaco com validade do login", token: "token-opaco-lembrado", want: opaqueExpiry, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TokenExpiry(tt.token)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("TokenExpiry = %v, %v; esperado %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTokenNeedsRenewal(t *testing.T) {
+	now := time.Date(2024, 3, 15, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{name: "expira depois da margem", token: testJWT(`{"exp":1710527400}`)},             // 18:30
+		{name: "expira dentro da margem", token: testJWT(`{"exp":1710526200}`), want: true}, // 18:10
+		{name: "já expirado", token: testJWT(`{"exp":1710522000}`), want: true},             // 17:00
+		{name: "validade desconhecida", token: "opaco"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TokenNeedsRenewal(tt.token, now); got != tt.want {
+				t.Errorf("TokenNeedsRenewal = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+const (
+	// daemonRefreshInterval acompanha o ciclo de refresh automático da TUI.
+	daemonRefreshInterval = 10 * time.Minute
+	// daemonAlertInterval é a frequência de reavaliação do alerta de
+	// intervalo; breakAlertDue já limita o envio a um a cada 20 min.
+	daemonAlertInterval = 30 * time.Second
+)
+
+// daemon mantém uma única sessão com a Senior em segundo plano: atualiza os
+// eventos periodicamente, dispara as notificações e atende TUI, CLI e barras
+// de status pelo socket Unix.
+type daemon struct {
+	mu        sync.Mutex
+	session   *session
+	events    []core.ClockingEvent
+	msg       eventMsg
+	fetchedAt time.Time
+	// fingerprint identifica o conjunto de marcações da última busca (ver
+	// eventsFingerprint).
+	fingerprint      string
+	lastNotification time.Time
+	broker           *eventBroker
+	metricsTextfile  string
+	logger           *log.Logger
+}
+
+const daemonUsage = `Uso: clockwerk daemon [--http 127.0.0.1:7788] [--http-origin URL] [--metrics-textfile ARQUIVO]
+
+Executa o Clockwerk sem interface: busca as marcações a cada 10 minutos,
+envia as notificações de intervalo e atende a TUI e os demais comandos pelo
+socket Unix abaixo, evitando que cada um faça login e consulte a Senior
+separadamente.
+
+Socket: %s
+
+Opções:
+  --http ENDEREÇO      serve também a API HTTP local (apenas localhost):
+                         GET  /status
+                         GET  /history?from=AAAA-MM-DD&to=AAAA-MM-DD
+                         POST /punch  (responde 428 com confirmToken; reenvie
+                                       com ?confirm=<token> em até 1 min)
+                         GET  /events (SSE: "punches" e "alert")
+                         GET  /metrics (formato Prometheus)
+  --http-origin URL    origem liberada via CORS para dashboards no navegador
+  --metrics-textfile ARQUIVO
+                       grava as métricas a cada atualização para o textfile
+                       collector do node_exporter (ex.: .../clockwerk.prom)
+`
+
+func runDaemon(ctx context.Context, args []string) int {
+	fs := newFlagSet("daemon")
+	httpAddr := fs.String("http", "", "endereço local da API HTTP (ex.: 127.0.0.1:7788)")
+	httpOrigin := fs.String("http-origin", "", "origem liberada via CORS")
+	metricsTextfile := fs.String("metrics-textfile", "", "arquivo .prom para o node_exporter")
+	fs.Usage = func() { fmt.Fprintf(os.Stderr, daemonUsage, core.GetSocketPath()) }
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if *httpAddr != "" {
+		if err := validateLoopbackAddr(*httpAddr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+
+	// O encerramento (Ctrl+C ou SIGTERM) cancela também as requisições à
+	// Senior em andamento.
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+	defer stop()
+
+	s, err := newDirectSession(ctx)
+	if err != nil {
+		return reportError(err)
+	}
+
+	listener, err := core.ListenDaemonSocket()
+	if err != nil {
+		return reportError(err)
+	}
+	defer listener.Close()
+
+	d := &daemon{
+		session:         s,
+		broker:          newEventBroker(),
+		metricsTextfile: *metricsTextfile,
+		logger:          log.New(os.Stderr, "clockwerk: ", log.LstdFlags),
+	}
+
+	d.logger.Printf("daemon escutando em %s", core.GetSocketPath())
+
+	// Falhas transitórias nas leituras periódicas são repetidas com backoff,
+	// antecipando a tentativa assim que a Senior volta a aceitar conexões.
+	var retry, probe <-chan time.Time
+	attempt := 0
+	refresh := func(what string) {
+		d.mu.Lock()
+		err := d.refresh()
+		d.mu.Unlock()
+
+		retry, probe = nil, nil
+		if err == nil {
+			attempt = 0
+			return
+		}
+		if !core.Retryable(err) {
+			attempt = 0
+			d.logger.Printf("falha %s: %v", what, err)
+			return
+		}
+		attempt++
+		delay := core.Backoff(attempt)
+		d.logger.Printf("falha %s: %v (nova tentativa em %s)", what, err, delay.Round(time.Second))
+		retry = time.After(delay)
+		if errors.Is(err, senior.ErrNetwork) || errors.Is(err, senior.ErrProxy) {
+			probe = time.After(core.ProbeInterval)
+		}
+	}
+
+	refresh("na busca inicial de eventos")
+
+	go d.serve(listener)
+
+	if *httpAddr != "" {
+		server := &http.Server{
+			Addr:              *httpAddr,
+			Handler:           newAPIServer(d, *httpAddr, *httpOrigin).handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				d.logger.Printf("erro na API HTTP: %v", err)
+			}
+		}()
+		defer server.Close()
+		d.logger.Printf("API HTTP em http://%s", *httpAddr)
+	}
+
+	refreshTicker := time.NewTicker(daemonRefreshInterval)
+	defer refreshTicker.Stop()
+	alertTicker := time.NewTicker(daemonAlertInterval)
+	defer alertTicker.Stop()
+	// Na volta de uma suspensão os tickers só disparam no próximo ciclo; o
+	// logind avisa antes disso.
+	resumed := watchResume(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Printf("encerrando daemon")
+			return exitOK
+		case <-refreshTicker.C:
+			refresh("ao atualizar eventos")
+		case <-retry:
+			refresh("ao atualizar eventos")
+		case <-resumed:
+			refresh("ao retomar da suspensão")
+		case <-probe:
+			if core.Online(ctx) {
+				refresh("ao atualizar eventos")
+			} else {
+				probe = time.After(core.ProbeInterval)
+			}
+		case <-alertTicker.C:
+			now := core.Now()
+			d.mu.Lock()
+			if elapsed, due := breakAlertDue(d.msg, d.lastNotification, now); due {
+				go notifyBreak(elapsed)
+				message, urgency := handleCreateMessageNotification(elapsed)
+				d.broker.publish("alert", alertEvent{
+					Message:        message,
+					Urgency:        urgency,
+					ElapsedSeconds: int64(elapsed / time.Second),
+				})
+				d.lastNotification = now
+			}
+			d.mu.Unlock()
+		}
+	}
+}
+
+// refresh busca os eventos na Senior e notifica quando surgem novas marcações
+// de hoje. Deve ser chamado com d.mu travado.
+func (d *daemon) refresh() error {
+	events, err := d.session.clockingEvents()
+	if err != nil {
+		return err
+	}
+
+	msg, err := newEventMsg(events)
+	if err != nil {
+		return err
+	}
+
+	today := core.TodayKey()
+	before := len(d.msg.clocking[today])
+	hadData := !d.fetchedAt.IsZero()
+	fingerprint := eventsFingerprint(events)
+	changed := hadData && fingerprint != d.fingerprint
+
+	d.events = events
+	d.msg = msg
+	d.fetchedAt = time.Now()
+	d.fingerprint = fingerprint
+
+	if hadData && len(msg.clocking[today]) > before {
+		go handleDesktopNotification("Clockwerk", "Marcações atualizadas.", "low")
+	}
+	// Qualquer mudança é avisada aos clientes da API, inclusive ajustes e
+	// remoções em dias passados, para que busquem o histórico de novo.
+	if changed {
+		d.broker.publish("punches", buildStatusReport(msg, core.Now()))
+	}
+
+	if d.metricsTextfile != "" {
+		if err := writeMetricsTextfile(d.metricsTextfile, d.msg, d.fetchedAt, core.Now()); err != nil {
+			d.logger.Printf("%v", err)
+		}
+	}
+
+	return nil
+}
+
+// eventsFingerprint resume as marcações (ID, data e hora), independente da
+// ordem, para detectar inclusões, alterações e remoções entre duas buscas.
+func eventsFingerprint(events []core.ClockingEvent) string {
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, event.ID+"|"+event.DateEvent+"|"+event.TimeEvent)
+	}
+	sort.Strings(lines)
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (d *daemon) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			d.logger.Printf("erro ao aceitar conexão: %v", err)
+			continue
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *daemon) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	var req core.IPCRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	if req.Method == core.IPCMethodRange {
+		// A busca por período pode percorrer várias páginas.
+		conn.SetDeadline(time.Now().Add(2 * time.Minute))
+	}
+
+	resp := d.dispatch(req)
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		d.logger.Printf("erro ao responder %q: %v", req.Method, err)
+	}
+}
+
+// dispatch executa um método do protocolo. As chamadas à Senior são
+// serializadas pelo mutex, então clientes simultâneos não duplicam requisições.
+func (d *daemon) dispatch(req core.IPCRequest) core.IPCResponse {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch req.Method {
+	case core.IPCMethodEvents:
+		if d.fetchedAt.IsZero() {
+			if err := d.refresh(); err != nil {
+				return core.IPCErrorResponse(err)
+			}
+		}
+		return d.snapshot()
+
+	case core.IPCMethodRefresh:
+		if err := d.refresh(); err != nil {
+			return core.IPCErrorResponse(err)
+		}
+		return d.snapshot()
+
+	case core.IPCMethodPunch:
+		if d.fetchedAt.IsZero() {
+			if err := d.refresh(); err != nil {
+				return core.IPCErrorResponse(err)
+			}
+		}
+
+		// A marcação só é repetida quando a Senior recusou o token (ver
+		// session.punch); nas demais falhas o cliente decide se tenta de novo.
+		punched, err := d.session.punch(d.msg)
+		if err != nil {
+			return core.IPCErrorResponse(err)
+		}
+		d.logger.Printf("marcação registrada: %s %s", punched.dateEvent, punched.timeEvent)
+
+		if err := d.refresh(); err != nil {
+			d.logger.Printf("falha ao atualizar eventos após marcação: %v", err)
+		}
+
+		resp := d.snapshot()
+		resp.DateEvent = punched.dateEvent
+		resp.TimeEvent = punched.timeEvent
+		return resp
+
+	case core.IPCMethodRange:
+		events, err := d.session.clockingEventsInRange(req.From, req.To)
+		if err != nil {
+			return core.IPCErrorResponse(err)
+		}
+		return core.IPCResponse{FetchedAt: time.Now(), Events: events}
+
+	default:
+		return core.IPCResponse{Error: fmt.Sprintf("método desconhecido: %q", req.Method)}
+	}
+}
+
+func (d *daemon) snapshot() core.IPCResponse {
+	skew, _ := core.ClockSkew()
+	return core.IPCResponse{FetchedAt: d.fetchedAt, Events: d.events, ClockSkew: skew}
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+func TestEventsFingerprint(t *testing.T) {
+	base := []core.ClockingEvent{
+		{ID: "a", DateEvent: "2024-03-14", TimeEvent: "08:00:00"},
+		{ID: "b", DateEvent: "2024-03-15", TimeEvent: "08:00:00"},
+	}
+
+	tests := []struct {
+		name    string
+		events  []core.ClockingEvent
+		changed bool
+	}{
+		{name: "mesmas marcações", events: base},
+		{
+			name: "outra ordem",
+			events: []core.ClockingEvent{
+				base[1], base[0],
+			},
+		},
+		{
+			name: "hora ajustada em dia passado",
+			events: []core.ClockingEvent{
+				{ID: "a", DateEvent: "2024-03-14", TimeEvent: "08:05:00"},
+				base[1],
+			},
+			changed: true,
+		},
+		{
+			name: "marcação movida de dia",
+			events: []core.ClockingEvent{
+				{ID: "a", DateEvent: "2024-03-13", TimeEvent: "08:00:00"},
+				base[1],
+			},
+			changed: true,
+		},
+		{name: "marcação removida", events: base[1:], changed: true},
+		{
+			name: "marcação nova",
+			events: append(append([]core.ClockingEvent(nil), base...),
+				core.ClockingEvent{ID: "c", DateEvent: "2024-03-15", TimeEvent: "12:00:00"}),
+			changed: true,
+		},
+	}
+
+	want := eventsFingerprint(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := eventsFingerprint(tt.events) != want; changed != tt.changed {
+				t.Errorf("mudou = %v, esperado %v", changed, tt.changed)
+			}
+		})
+	}
+}
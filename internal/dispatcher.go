@@ -1,46 +1,120 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
 	"github.com/diegodario88/clockwerk/internal/ui"
 )
 
-// applyEventMsg atualiza o eventMsg do modelo e recalcula punchCount, elapsed
-// e timerRunning a partir das marcações de hoje.
+// applyEventMsg atualiza o eventMsg do modelo e recalcula today, punchCount,
+// elapsed e timerRunning a partir das marcações de hoje.
 func applyEventMsg(m *clockTimer, msg eventMsg) {
 	m.eventMsg = msg
-	m.elapsed = 0
-	m.timerRunning = false
-
-	maybeTodayClock, exists := m.eventMsg.clocking[core.TodayKey]
-	if exists {
-		m.punchCount = len(maybeTodayClock)
-		if len(maybeTodayClock)%2 != 0 {
-			m.timerRunning = true
-			loc := time.FixedZone("UTC-3", -3*3600)
-			nowInUTC3 := time.Now().In(loc)
-			formatted := nowInUTC3.Format(core.TimeLayout)
-			parsedTime, err := time.ParseInLocation(core.TimeLayout, formatted, loc)
-			if err != nil {
-				log.Println("Erro ao parsear o tempo:", err)
-			}
-			maybeTodayClock = append(maybeTodayClock, clockingMsg{eventTime: parsedTime})
-		}
+	m.today = core.TodayKey()
+	m.punchCount = len(m.eventMsg.clocking[m.today])
+	updateElapsed(m)
+}
 
-		for i := 0; i < len(maybeTodayClock)-1; i += 2 {
-			startTime := maybeTodayClock[i].eventTime
-			endTime := maybeTodayClock[i+1].eventTime
-			m.elapsed += endTime.Sub(startTime)
+// updateElapsed recalcula elapsed e timerRunning a partir das marcações de
+// hoje a cada tick, em vez de somar 1s por tick: ticks atrasados ou uma
+// suspensão não desviam o timer. O bloco em andamento é medido pelo relógio
+// da Senior, que carimbou as marcações, e não pelo local (ver core.Now).
+func updateElapsed(m *clockTimer) {
+	punches := todayPunches(m.eventMsg)
+	m.timerRunning = len(punches)%2 != 0
+	if m.timerRunning {
+		punches = append(punches, core.Now())
+	}
+	m.elapsed = core.WorkedDuration(punches)
+}
+
+// rolloverDay vira a sessão para o novo dia quando a TUI atravessa a
+// meia-noite aberta: zera o timer e o recibo da última marcação e libera os
+// alertas de intervalo, que passam a olhar só para as marcações do novo dia.
+func rolloverDay(m *clockTimer) {
+	applyEventMsg(m, m.eventMsg)
+	m.punchReceipt = nil
+	m.lastNotification = time.Time{}
+}
+
+// todayPunches devolve os horários das marcações de hoje, em ordem crescente.
+func todayPunches(msg eventMsg) []time.Time {
+	var punches []time.Time
+	for _, event := range msg.clocking[core.TodayKey()] {
+		punches = append(punches, event.eventTime)
+	}
+	return punches
+}
+
+// predictTodayExit aplica core.PredictExit ao expediente e às marcações de hoje.
+func predictTodayExit(msg eventMsg, now time.Time) (time.Time, bool) {
+	exp, ok := core.ParseTimeTable(msg.timeTable)
+	if !ok {
+		return time.Time{}, false
+	}
+	return core.PredictExit(exp, todayPunches(msg), now)
+}
+
+// requestHistoryRange busca na Senior o período exibido pelo Histórico quando
+// os dados atuais não o cobrem. Cada período é pedido uma única vez, então
+// navegar além do início do histórico não repete a busca.
+func requestHistoryRange(m *clockTimer) tea.Cmd {
+	now := time.Now()
+	earliest := earliestDateKey(m.eventMsg.clocking)
+
+	var from, to string
+	if m.historyView == 0 {
+		if len(selectWeekDates(m.eventMsg.clocking, m.historyOffset)) == 5 {
+			return nil
+		}
+		// Três semanas antes da marcação mais antiga cobrem cinco dias úteis
+		// mesmo com feriados e folgas no caminho.
+		start, ok := parseDateKey(earliest)
+		if !ok {
+			start = now
+		}
+		from = start.AddDate(0, 0, -21).Format("2006-01-02")
+		to = start.AddDate(0, 0, -1).Format("2006-01-02")
+	} else {
+		month := historyMonth(now, m.historyOffset)
+		first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+		from = first.Format("2006-01-02")
+		if earliest != "" && earliest <= from {
+			return nil
 		}
+		to = first.AddDate(0, 1, -1).Format("2006-01-02")
+	}
+
+	if m.historyRequested == nil {
+		m.historyRequested = make(map[string]bool)
+	}
+	rangeKey := from + ".." + to
+	if m.historyRequested[rangeKey] {
+		return nil
+	}
+	m.historyRequested[rangeKey] = true
+	m.historyLoading = true
+
+	return handleGetClockingEventRange(m.ctx, m.token, from, to)
+}
+
+// historyExhausted indica que o período exibido é anterior a todas as
+// marcações conhecidas e já foi buscado, ou seja, não há mais para onde voltar.
+func historyExhausted(m *clockTimer) bool {
+	if m.historyView == 0 {
+		return len(selectWeekDates(m.eventMsg.clocking, m.historyOffset)) == 0
 	}
+	month := historyMonth(time.Now(), m.historyOffset)
+	last := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location())
+	return last.Format("2006-01-02") < earliestDateKey(m.eventMsg.clocking)
 }
 
 // scheduleTick mantém um único tick de 1s ativo no dashboard (usado tanto pelo
@@ -51,6 +125,7 @@ func scheduleTick(m *clockTimer) tea.Cmd {
 		return nil
 	}
 	m.tickScheduled = true
+	m.tickScheduledAt = time.Now().Round(0)
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg{} })
 }
 
@@ -66,6 +141,27 @@ func scheduleRefresh(m *clockTimer) tea.Cmd {
 	return tea.Tick(10*time.Minute, func(t time.Time) tea.Msg { return refreshTickMsg{} })
 }
 
+// saveRenewedToken registra a renovação de m.token (reason é um dos
+// core.Renewal*) e persiste as credenciais quando o usuário optou por
+// mantê-las.
+func saveRenewedToken(m *clockTimer, reason string) {
+	creds := core.UserCredentials{
+		Domain:   m.domain,
+		CPF:      m.cpf,
+		Password: m.password,
+		Renewals: m.tokenRenewals,
+	}
+	creds.SetRenewedToken(m.token, reason, time.Now())
+	m.tokenRenewals = creds.Renewals
+
+	if !m.keepLogged {
+		return
+	}
+	if err := core.SaveCredentials(creds); err != nil {
+		log.Printf("Erro ao salvar credenciais: %v", err)
+	}
+}
+
 func dispatchWindowSizeChange(msg tea.WindowSizeMsg, m *clockTimer) (tea.Model, tea.Cmd) {
 	m.width = msg.Width
 	m.height = msg.Height
@@ -90,9 +186,15 @@ func dispatchInputCpf(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 
 	if m.cpfForm.State == huh.StateCompleted {
 		m.cpf = m.cpfForm.GetString("cpf")
-		m.domain = m.cpfForm.GetString("domain")
+		m.domain = senior.TenantFromDomain(m.cpfForm.GetString("domain"))
+		m.sso = m.cpfForm.GetBool("sso")
+		core.SetTenantDomain(m.domain)
 		m.step = 1
 		m.paginator.NextPage()
+		if m.sso {
+			m.tokenForm = ui.NewTokenForm()
+			return m, m.tokenForm.Init()
+		}
 		m.passwordForm = ui.NewPasswordForm(m.passwordForm.GetString("password"))
 		return m, m.passwordForm.Init()
 	}
@@ -101,6 +203,10 @@ func dispatchInputCpf(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 }
 
 func dispatchInputPassword(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
+	if m.sso {
+		return dispatchInputToken(msg, m)
+	}
+
 	var cmd tea.Cmd
 	newForm, c := m.passwordForm.Update(msg)
 	if f, ok := newForm.(*huh.Form); ok {
@@ -113,7 +219,7 @@ func dispatchInputPassword(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 		if !m.passwordForm.GetBool("next") {
 			m.step = 0
 			m.paginator.PrevPage()
-			m.cpfForm = ui.NewCPFForm(m.cpfForm.GetString("domain"), m.cpfForm.GetString("cpf"))
+			m.cpfForm = ui.NewCPFForm(m.cpfForm.GetString("domain"), m.cpfForm.GetString("cpf"), m.sso)
 			return m, m.cpfForm.Init()
 		}
 		m.password = m.passwordForm.GetString("password")
@@ -126,6 +232,67 @@ func dispatchInputPassword(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// dispatchInputToken trata a etapa 2 do login por token (SSO). Quando o token
+// anterior expirou, o novo é validado direto, sem repetir a etapa 3.
+func dispatchInputToken(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	newForm, c := m.tokenForm.Update(msg)
+	if f, ok := newForm.(*huh.Form); ok {
+		m.tokenForm = f
+	}
+
+	cmd = c
+
+	if m.tokenForm.State == huh.StateCompleted {
+		if !m.tokenForm.GetBool("next") {
+			m.step = 0
+			m.tokenExpired = false
+			m.paginator.PrevPage()
+			m.cpfForm = ui.NewCPFForm(m.domain, m.cpf, true)
+			return m, m.cpfForm.Init()
+		}
+		m.token = core.NormalizeBearerToken(m.tokenForm.GetString("token"))
+		m.password = ""
+		if m.tokenExpired {
+			m.tokenExpired = false
+			m.step = 3
+			return m, tea.Batch(authenticateCmd(m), m.spinner.Tick)
+		}
+		m.step = 2
+		m.paginator.NextPage()
+		m.keepForm = ui.NewKeepForm(m.keepLogged)
+		return m, m.keepForm.Init()
+	}
+
+	return m, cmd
+}
+
+// authenticateCmd autentica com a senha ou valida o token colado, conforme a
+// forma de login escolhida; os dois caminhos conferem o tenant do domínio.
+func authenticateCmd(m *clockTimer) tea.Cmd {
+	if m.sso {
+		return handleTokenValidation(m.ctx, m.token)
+	}
+	return handleOnboardingLogin(m.ctx, fmt.Sprintf("%s@%s", m.cpf, m.domain), m.password)
+}
+
+// promptToken volta ao formulário do token quando a Senior recusa um token de
+// SSO: sem senha, não há como renovar o login sozinho.
+func promptToken(m *clockTimer) (tea.Model, tea.Cmd) {
+	m.step = 1
+	m.tokenExpired = true
+	m.refreshing = false
+	m.failedMsg = FailedMsg{}
+	resetRetry(m)
+	// Ticks e refresh pendentes chegam ao formulário e são descartados; o
+	// dashboard os reagenda ao voltar.
+	m.tickScheduled = false
+	m.refreshScheduled = false
+	m.paginator.Page = 1
+	m.tokenForm = ui.NewTokenForm()
+	return m, m.tokenForm.Init()
+}
+
 func dispatchInputKeep(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	newForm, c := m.keepForm.Update(msg)
@@ -139,16 +306,17 @@ func dispatchInputKeep(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 		if !m.keepForm.GetBool("next") {
 			m.step = 1
 			m.paginator.PrevPage()
-			m.passwordForm = ui.NewPasswordForm(m.passwordForm.GetString("password"))
 			m.keepLogged = m.keepForm.GetBool("keep")
+			if m.sso {
+				m.tokenForm = ui.NewTokenForm()
+				return m, m.tokenForm.Init()
+			}
+			m.passwordForm = ui.NewPasswordForm(m.passwordForm.GetString("password"))
 			return m, m.passwordForm.Init()
 		}
 		m.keepLogged = m.keepForm.GetBool("keep")
 		m.step = 3
-		return m, tea.Batch(
-			handleAuthentication(fmt.Sprintf("%s@%s", m.cpf, m.domain), m.password),
-			m.spinner.Tick,
-		)
+		return m, tea.Batch(authenticateCmd(m), m.spinner.Tick)
 	}
 
 	return m, cmd
@@ -161,15 +329,23 @@ func dispatchAuthSpinner(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case FailedMsg:
 		m.failedMsg = msg
+		if core.Retryable(msg.err) {
+			return m, scheduleRetry(m, msg.err)
+		}
+		resetRetry(m)
 		return m, nil
 
 	case LoginMsg:
 		m.loginMsg = msg
 		m.step = 4
 		m.token = msg.token
-		m.failedMsg = FailedMsg{error: ""}
+		m.failedMsg = FailedMsg{}
+		resetRetry(m)
 
-		if m.keepLogged {
+		if m.hasAuthRecover {
+			// Login refeito após a Senior recusar o token salvo.
+			saveRenewedToken(m, core.RenewalExpired)
+		} else if m.keepLogged {
 			creds := core.UserCredentials{
 				Domain:   m.domain,
 				CPF:      m.cpf,
@@ -180,17 +356,21 @@ func dispatchAuthSpinner(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 				log.Printf("Erro ao salvar credenciais: %v", err)
 			}
 		}
-		return m, tea.Batch(handleGetClockingEvent(m.token), m.spinner.Tick)
+		return m, tea.Batch(handleGetClockingEvent(m.ctx, m.token), m.spinner.Tick)
 
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, m.keys.Retry):
-			if m.failedMsg.error != "" {
-				m.failedMsg = FailedMsg{error: ""}
+			if retryPending(m) {
+				m.failedMsg = FailedMsg{}
+				return m, retryNow(m)
+			}
+			if m.failedMsg.err != nil {
+				m.failedMsg = FailedMsg{}
 				m.step = 0
 				m.paginator.PrevPage()
 				m.paginator.PrevPage()
-				m.cpfForm = ui.NewCPFForm(m.cpfForm.GetString("domain"), m.cpfForm.GetString("cpf"))
+				m.cpfForm = ui.NewCPFForm(m.cpfForm.GetString("domain"), m.cpfForm.GetString("cpf"), m.sso)
 				return m, m.cpfForm.Init()
 			}
 		}
@@ -205,30 +385,46 @@ func dispatchEventsSpinner(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case FailedMsg:
-		if strings.Contains(msg.error, "Unauthorized") && !m.hasAuthRecover {
+		if errors.Is(msg.err, senior.ErrTokenExpired) && m.sso {
+			return promptToken(m)
+		}
+		if errors.Is(msg.err, senior.ErrTokenExpired) && !m.hasAuthRecover {
 			core.DeleteCredentials()
 			m.step = 3
 			m.hasAuthRecover = true
 			return m, tea.Batch(
-				handleAuthentication(fmt.Sprintf("%s@%s", m.cpf, m.domain), m.password),
+				handleAuthentication(m.ctx, fmt.Sprintf("%s@%s", m.cpf, m.domain), m.password),
 				m.spinner.Tick,
 			)
 		}
 		m.failedMsg = msg
+		if core.Retryable(msg.err) {
+			return m, scheduleRetry(m, msg.err)
+		}
+		resetRetry(m)
 		return m, nil
 
 	case eventMsg:
 		m.step = 5
+		m.failedMsg = FailedMsg{}
+		m.hasAuthRecover = false
+		resetRetry(m)
 		applyEventMsg(m, msg)
+		m.fetchedAt = time.Now()
+		m.stale = false
 		return m, tea.Batch(cmd, scheduleTick(m), scheduleRefresh(m))
 
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, m.keys.Retry):
-			if m.failedMsg.error != "" {
+			if retryPending(m) {
+				m.failedMsg = FailedMsg{}
+				return m, retryNow(m)
+			}
+			if m.failedMsg.err != nil {
 				m.step = 0
-				m.failedMsg = FailedMsg{error: ""}
-				m.cpfForm = ui.NewCPFForm(m.cpfForm.GetString("domain"), m.cpfForm.GetString("cpf"))
+				m.failedMsg = FailedMsg{}
+				m.cpfForm = ui.NewCPFForm(m.cpfForm.GetString("domain"), m.cpfForm.GetString("cpf"), m.sso)
 				return m, m.cpfForm.Init()
 			}
 		}
@@ -244,25 +440,25 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 	// vivos mesmo com formulários abertos ou com o timer parado.
 	if _, ok := msg.(tickMsg); ok {
 		m.tickScheduled = false
+		refresh := tickResumed(m)
+		if m.today != core.TodayKey() {
+			rolloverDay(m)
+			refresh = true
+		}
+		updateElapsed(m)
 		if m.timerRunning {
-			m.elapsed += time.Second
-			maybeTodayClock, exists := m.eventMsg.clocking[core.TodayKey]
-			if exists {
-				lastPunchTime := maybeTodayClock[m.punchCount-1].eventTime
-				currentElapsed := time.Since(lastPunchTime)
-
-				if currentElapsed.Hours() >= 4 {
-					if m.lastNotification.IsZero() || time.Since(m.lastNotification) >= 20*time.Minute {
-						ce := currentElapsed
-						go func(elapsed time.Duration) {
-							message, urgency := handleCreateMessageNotification(elapsed)
-							handleDesktopNotification("Alerta Clockwerk", message, urgency)
-						}(ce)
-						m.lastNotification = time.Now()
-					}
+			if elapsed, due := breakAlertDue(m.eventMsg, m.lastNotification, core.Now()); due {
+				// Com um daemon ativo, os alertas são dele; a TUI só marca o
+				// horário para não reavaliar a cada tick.
+				if !core.DaemonRunning() {
+					go notifyBreak(elapsed)
 				}
+				m.lastNotification = core.Now()
 			}
 		}
+		if refresh {
+			return m, tea.Batch(scheduleTick(m), refreshNow(m))
+		}
 		return m, scheduleTick(m)
 	}
 
@@ -277,6 +473,9 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 				if err := core.DeleteCredentials(); err != nil {
 					log.Printf("Erro ao deletar credenciais: %v", err)
 				}
+				if err := core.DeleteEventCache(); err != nil {
+					log.Printf("Erro ao deletar cache de eventos: %v", err)
+				}
 			}
 			m.forgetForm = nil
 			return m, scheduleTick(m)
@@ -294,12 +493,8 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 		cmd = c
 		if m.punchForm.State == huh.StateCompleted {
 			if m.punchForm.GetBool("confirm") {
-				m.step = 6
 				m.punchForm = nil
-				return m, tea.Batch(
-					handlePostClockingEvent(m.token, m.eventMsg),
-					m.spinner.Tick,
-				)
+				return m, startPunch(m)
 			} else {
 				m.punchForm = nil
 				return m, scheduleTick(m)
@@ -316,7 +511,7 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 			if m.activeTab != 0 {
 				return m, nil
 			}
-			m.punchForm = ui.NewPunchConfirmForm()
+			m.punchForm = ui.NewPunchConfirmForm(summarizePunch(m.eventMsg, core.Now()))
 			return m, m.punchForm.Init()
 		case key.Matches(msg, m.keys.ForgetCreds):
 			if m.activeTab != 0 {
@@ -327,6 +522,25 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.ToggleHistoryView):
 			if m.activeTab == 1 {
 				m.historyView = (m.historyView + 1) % 2
+				m.historyOffset = 0
+				m.historyError = ""
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.PreviousPeriod):
+			if m.activeTab != 1 || m.historyLoading {
+				return m, nil
+			}
+			m.historyOffset++
+			m.historyError = ""
+			cmd := requestHistoryRange(m)
+			if cmd == nil && historyExhausted(m) {
+				m.historyOffset--
+			}
+			return m, cmd
+		case key.Matches(msg, m.keys.NextPeriod):
+			if m.activeTab == 1 && m.historyOffset > 0 {
+				m.historyOffset--
+				m.historyError = ""
 			}
 			return m, nil
 		case key.Matches(msg, m.keys.MoveBack):
@@ -335,10 +549,10 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 			} else {
 				m.activeTab = 2
 			}
-			return m, nil
+			return m, ensureAudits(m)
 		case key.Matches(msg, m.keys.MoveForward):
 			m.activeTab = (m.activeTab + 1) % 3
-			return m, nil
+			return m, ensureAudits(m)
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 		case key.Matches(msg, m.keys.Exit):
@@ -348,23 +562,64 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 	case refreshTickMsg:
 		m.refreshScheduled = false
 		m.refreshing = true
-		return m, handleGetClockingEvent(m.token)
+		return m, fetchEventsCmd(m)
 
 	case eventMsg:
 		wasRefreshing := m.refreshing
 		applyEventMsg(m, msg)
 		m.refreshing = false
+		m.fetchedAt = time.Now()
+		m.stale = false
+		m.hasAuthRecover = false
+		resetRetry(m)
+		m.auditsLoaded = false
 
 		if wasRefreshing {
 			go handleDesktopNotification("Clockwerk", "Marcações atualizadas.", "low")
 		}
-		return m, tea.Batch(scheduleTick(m), scheduleRefresh(m))
+		return m, tea.Batch(scheduleTick(m), scheduleRefresh(m), ensureAudits(m))
+
+	case auditsMsg:
+		m.audits = msg.audits
+		return m, nil
+
+	case historyRangeMsg:
+		m.historyLoading = false
+		if msg.err != nil {
+			// Libera o período para uma nova tentativa ao navegar de novo.
+			delete(m.historyRequested, msg.from+".."+msg.to)
+			m.historyError = msg.err.Error()
+			return m, nil
+		}
+		m.eventMsg = withClocking(m.eventMsg, msg.clocking)
+		m.auditsLoaded = false
+		return m, ensureAudits(m)
+
+	case LoginMsg:
+		// Login refeito em segundo plano após um token expirado no refresh.
+		m.token = msg.token
+		saveRenewedToken(m, core.RenewalExpired)
+		return m, handleGetClockingEvent(m.ctx, m.token)
 
 	case FailedMsg:
 		// Falha de refresh em segundo plano não derruba o dashboard: mantém os
-		// dados atuais, remove o indicador e reagenda o próximo ciclo.
+		// dados atuais marcados como desatualizados. Um token expirado é
+		// renovado uma vez por sequência de falhas; falhas transitórias são
+		// repetidas com backoff e as demais aguardam o próximo ciclo.
 		if m.refreshing {
+			m.stale = true
+			if errors.Is(msg.err, senior.ErrTokenExpired) && m.sso {
+				return promptToken(m)
+			}
+			if errors.Is(msg.err, senior.ErrTokenExpired) && !m.hasAuthRecover && m.password != "" {
+				m.hasAuthRecover = true
+				return m, handleAuthentication(m.ctx, fmt.Sprintf("%s@%s", m.cpf, m.domain), m.password)
+			}
 			m.refreshing = false
+			if core.Retryable(msg.err) {
+				return m, scheduleRetry(m, msg.err)
+			}
+			resetRetry(m)
 			return m, scheduleRefresh(m)
 		}
 		m.step = 4
@@ -374,16 +629,3 @@ func dispatchDashboard(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
-
-func dispatchPunch(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	m.spinner, cmd = m.spinner.Update(msg)
-
-	switch msg.(type) {
-	case PostClockingMsg:
-		m.step = 4
-		return m, tea.Batch(handleGetClockingEvent(m.token), m.spinner.Tick)
-	}
-
-	return m, cmd
-}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+const gatewayUsage = `Uso: clockwerk gateway serve [opções]
+
+Sobe o gateway de login (POST /senior/login) para que a empresa o mantenha
+internamente. Ele recebe {"user", "password"}, autentica direto na plataforma
+da Senior e devolve {"token"}, no mesmo contrato esperado pelos clientes com
+"useGateway" ou "gatewayFallback" na configuração. O corpo das requisições,
+usuários e senhas nunca são registrados no log.
+
+Opções:
+  --listen ENDEREÇO    endereço HTTP (padrão 127.0.0.1:8089)
+  --upstream URL       plataforma da Senior (padrão %s)
+  --tenant TENANT      fixa o segmento /t/<tenant>/ das rotas; por padrão ele
+                       vem do domínio do usuário (cpf@dominio)
+  --rate N             tentativas de login por minuto, por IP e por usuário
+                       (padrão 10; 0 desliga o limite)
+  --burst N            tentativas seguidas antes do limite (padrão 5)
+  --trust-proxy        usa a última entrada de X-Forwarded-For, a do proxy
+                       reverso, como IP do cliente
+  --tls-cert ARQUIVO, --tls-key ARQUIVO
+                       serve HTTPS com o certificado e a chave informados
+
+Proxy, CAs adicionais e certificado de cliente para chegar à plataforma vêm do
+arquivo de configuração, como no restante do Clockwerk.
+`
+
+func runGateway(ctx context.Context, args []string) int {
+	if len(args) == 0 || args[0] != "serve" {
+		fmt.Fprintf(os.Stderr, gatewayUsage, senior.DefaultPlatformURL)
+		return exitUsage
+	}
+
+	fs := newFlagSet("gateway serve")
+	listen := fs.String("listen", "127.0.0.1:8089", "endereço HTTP")
+	upstream := fs.String("upstream", senior.DefaultPlatformURL, "plataforma da Senior")
+	tenant := fs.String("tenant", "", "tenant fixo das rotas da plataforma")
+	rate := fs.Float64("rate", 10, "tentativas de login por minuto, por IP e por usuário")
+	burst := fs.Int("burst", 5, "tentativas seguidas antes do limite")
+	trustProxy := fs.Bool("trust-proxy", false, "usa X-Forwarded-For como IP do cliente")
+	tlsCert := fs.String("tls-cert", "", "certificado TLS")
+	tlsKey := fs.String("tls-key", "", "chave do certificado TLS")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, gatewayUsage, senior.DefaultPlatformURL)
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return parseExitCode(err)
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fmt.Fprintln(os.Stderr, "erro: informe --tls-cert e --tls-key juntos")
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+	defer stop()
+
+	logger := log.New(os.Stderr, "clockwerk-gateway: ", log.LstdFlags)
+
+	// Proxy, CAs e certificado de cliente vêm do arquivo de configuração,
+	// como no restante do Clockwerk.
+	config, err := core.LoadConfig()
+	if err != nil {
+		return reportError(err)
+	}
+	config.PlatformURL = *upstream
+
+	client := senior.NewClient(senior.Config{
+		PlatformURL: *upstream,
+		Tenant:      *tenant,
+		UserAgent:   "clockwerk-gateway/" + core.Version,
+		AppVersion:  core.Version,
+	}, core.NewHTTPClient(config))
+
+	server := &http.Server{
+		Addr: *listen,
+		Handler: senior.NewGatewayHandler(client, senior.GatewayOptions{
+			RatePerMinute: *rate,
+			Burst:         *burst,
+			TrustProxy:    *trustProxy,
+			Logger:        logger,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		// Os logs de erro do próprio net/http não incluem corpos.
+		ErrorLog: logger,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if *tlsCert != "" {
+			errs <- server.ListenAndServeTLS(*tlsCert, *tlsKey)
+		} else {
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	scheme := "http"
+	if *tlsCert != "" {
+		scheme = "https"
+	}
+	tenantInfo := "tenant do domínio do usuário"
+	if *tenant != "" {
+		tenantInfo = "tenant " + *tenant
+	}
+	logger.Printf("gateway em %s://%s → %s (%s)", scheme, *listen, client.Config().PlatformURL, tenantInfo)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return reportError(err)
+		}
+	case <-ctx.Done():
+		logger.Printf("encerrando gateway")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}
+
+	return exitOK
+}
@@ -13,6 +13,8 @@ type keyMap struct {
 	MoveForward       key.Binding
 	Retry             key.Binding
 	ToggleHistoryView key.Binding
+	PreviousPeriod    key.Binding
+	NextPeriod        key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -64,6 +66,14 @@ var keys = keyMap{
 		key.WithKeys("v", "V"),
 		key.WithHelp("<v>", "Alternar período"),
 	),
+	PreviousPeriod: key.NewBinding(
+		key.WithKeys("[", "pgup"),
+		key.WithHelp("<[>", "Período anterior"),
+	),
+	NextPeriod: key.NewBinding(
+		key.WithKeys("]", "pgdown"),
+		key.WithHelp("<]>", "Período seguinte"),
+	),
 	Exit: key.NewBinding(
 		key.WithKeys("q", "Q"),
 		key.WithHelp("<q>", "Fechar"),
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+// writeMetrics escreve as métricas no formato texto do Prometheus. Sem dados
+// (fetchedAt zero), só as estatísticas das chamadas à API são emitidas.
+func writeMetrics(w io.Writer, msg eventMsg, fetchedAt time.Time, now time.Time) {
+	if !fetchedAt.IsZero() {
+		status := buildStatusReport(msg, now)
+
+		working := 0
+		if status.Working {
+			working = 1
+		}
+
+		writeMetricHeader(w, "clockwerk_worked_seconds_today", "gauge",
+			"Tempo trabalhado hoje, incluindo o bloco em andamento.")
+		fmt.Fprintf(w, "clockwerk_worked_seconds_today %d\n", status.WorkedSeconds)
+
+		writeMetricHeader(w, "clockwerk_working", "gauge",
+			"1 quando há uma jornada em andamento (número ímpar de marcações hoje).")
+		fmt.Fprintf(w, "clockwerk_working %d\n", working)
+
+		writeMetricHeader(w, "clockwerk_punches_today", "gauge",
+			"Quantidade de marcações de hoje.")
+		fmt.Fprintf(w, "clockwerk_punches_today %d\n", len(status.Punches))
+
+		if predicted, err := time.Parse(time.RFC3339, status.PredictedExit); err == nil {
+			writeMetricHeader(w, "clockwerk_predicted_exit_timestamp", "gauge",
+				"Horário previsto de saída de hoje (Unix, segundos).")
+			fmt.Fprintf(w, "clockwerk_predicted_exit_timestamp %d\n", predicted.Unix())
+		}
+
+		writeMetricHeader(w, "clockwerk_balance_seconds", "gauge",
+			"Saldo do período (dias completos com expediente válido).")
+		for _, period := range []string{"week", "month"} {
+			report := buildHistoryReport(msg, nil, period, now)
+			fmt.Fprintf(w, "clockwerk_balance_seconds{period=%q} %d\n", period, report.TotalBalanceSeconds)
+		}
+
+		writeMetricHeader(w, "clockwerk_last_refresh_timestamp", "gauge",
+			"Horário da última busca de eventos bem-sucedida (Unix, segundos).")
+		fmt.Fprintf(w, "clockwerk_last_refresh_timestamp %d\n", fetchedAt.Unix())
+	}
+
+	stats := core.APIStats()
+	if len(stats) == 0 {
+		return
+	}
+
+	writeMetricHeader(w, "clockwerk_api_requests_total", "counter",
+		"Chamadas à API da Senior por operação e resultado.")
+	for _, op := range stats {
+		for _, outcome := range []string{"ok", "http_4xx", "http_5xx", "network"} {
+			fmt.Fprintf(w, "clockwerk_api_requests_total{operation=%q,outcome=%q} %d\n",
+				op.Operation, outcome, op.Outcomes[outcome])
+		}
+	}
+
+	writeMetricHeader(w, "clockwerk_api_request_duration_seconds", "histogram",
+		"Latência das chamadas à API da Senior.")
+	for _, op := range stats {
+		for i, bound := range core.APILatencyBuckets {
+			fmt.Fprintf(w, "clockwerk_api_request_duration_seconds_bucket{operation=%q,le=%q} %d\n",
+				op.Operation, strconv.FormatFloat(bound, 'g', -1, 64), op.Buckets[i])
+		}
+		fmt.Fprintf(w, "clockwerk_api_request_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n",
+			op.Operation, op.Count)
+		fmt.Fprintf(w, "clockwerk_api_request_duration_seconds_sum{operation=%q} %g\n",
+			op.Operation, op.DurationSum.Seconds())
+		fmt.Fprintf(w, "clockwerk_api_request_duration_seconds_count{operation=%q} %d\n",
+			op.Operation, op.Count)
+	}
+}
+
+func writeMetricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeMetricsTextfile grava as métricas para o textfile collector do
+// node_exporter. A escrita vai para um arquivo temporário no mesmo diretório
+// e é renomeada no fim, para o coletor nunca ler um arquivo pela metade.
+func writeMetricsTextfile(path string, msg eventMsg, fetchedAt time.Time, now time.Time) error {
+	var buf bytes.Buffer
+	writeMetrics(&buf, msg, fetchedAt, now)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".clockwerk-*.prom.tmp")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo de métricas: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar métricas: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar métricas: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("erro ao ajustar permissões das métricas: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("erro ao publicar métricas em %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func runMetrics(ctx context.Context, args []string) int {
+	fs := newFlagSet("metrics")
+	textfile := fs.String("textfile", "", "grava no arquivo (textfile collector do node_exporter) em vez da saída padrão")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	session, err := newSession(ctx)
+	if err != nil {
+		return reportError(err)
+	}
+
+	msg, err := session.events()
+	if err != nil {
+		return reportError(err)
+	}
+
+	fetchedAt, now := time.Now(), core.Now()
+	if *textfile != "" {
+		if err := writeMetricsTextfile(*textfile, msg, fetchedAt, now); err != nil {
+			return reportError(err)
+		}
+		return exitOK
+	}
+
+	writeMetrics(os.Stdout, msg, fetchedAt, now)
+	return exitOK
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// outputFormat é o formato de saída dos comandos de leitura da CLI.
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch f := outputFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case outputTable, outputJSON, outputYAML:
+		return f, nil
+	}
+	return "", fmt.Errorf("formato de saída inválido %q (use json, yaml ou table)", s)
+}
+
+// writeReport serializa v no formato pedido; table delega a renderização
+// textual para quem conhece o relatório.
+func writeReport(w io.Writer, format outputFormat, v any, table func(io.Writer)) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		return encodeYAML(w, v)
+	default:
+		table(w)
+		return nil
+	}
+}
+
+// encodeYAML escreve v como YAML respeitando as tags json dos campos (nome e
+// omitempty) e a ordem de declaração. Cobre apenas o que os relatórios usam:
+// structs, slices, strings, números e booleanos.
+func encodeYAML(w io.Writer, v any) error {
+	var b strings.Builder
+	if err := yamlValue(&b, reflect.ValueOf(v), 0, false); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func yamlValue(b *strings.Builder, v reflect.Value, indent int, inList bool) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			b.WriteString("null\n")
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	pad := strings.Repeat("  ", indent)
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		first := true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, omitEmpty := jsonFieldName(field)
+			if name == "-" {
+				continue
+			}
+			fv := v.Field(i)
+			if omitEmpty && fv.IsZero() {
+				continue
+			}
+
+			// O primeiro campo de um item de lista fica na linha do "- ".
+			if !(first && inList) {
+				b.WriteString(pad)
+			}
+			first = false
+			b.WriteString(name + ":")
+
+			if isYAMLScalar(fv) {
+				b.WriteString(" ")
+				if err := yamlValue(b, fv, indent+1, false); err != nil {
+					return err
+				}
+				continue
+			}
+			if fv.Kind() == reflect.Slice && fv.Len() == 0 {
+				b.WriteString(" []\n")
+				continue
+			}
+			b.WriteString("\n")
+			if err := yamlValue(b, fv, indent+1, false); err != nil {
+				return err
+			}
+		}
+		if first {
+			b.WriteString("{}\n")
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			b.WriteString(pad + "- ")
+			item := v.Index(i)
+			if isYAMLScalar(item) {
+				if err := yamlValue(b, item, indent+1, false); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := yamlValue(b, item, indent+1, true); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()) + "\n")
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()) + "\n")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10) + "\n")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10) + "\n")
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'f', -1, 64) + "\n")
+	default:
+		return fmt.Errorf("yaml: tipo não suportado %s", v.Kind())
+	}
+
+	return nil
+}
+
+func isYAMLScalar(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array:
+		return false
+	}
+	return true
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	omitEmpty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty
+}
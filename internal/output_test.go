@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+type yamlPunch struct {
+	Time     string `json:"time"`
+	Platform string `json:"platform,omitempty"`
+}
+
+type yamlReport struct {
+	Date    string      `json:"date"`
+	Working bool        `json:"working"`
+	Seconds int64       `json:"workedSeconds"`
+	Ratio   float64     `json:"ratio,omitempty"`
+	Punches []yamlPunch `json:"punches"`
+	Tags    []string    `json:"tags,omitempty"`
+	Skipped string      `json:"-"`
+	Note    *string     `json:"note"`
+	hidden  string
+}
+
+func TestEncodeYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{
+			name: "struct com lista de structs e campos omitidos",
+			in: yamlReport{
+				Date:    "2024-03-15",
+				Working: true,
+				Seconds: 3600,
+				Punches: []yamlPunch{{Time: "08:00", Platform: "WEB"}, {Time: "12:00"}},
+				Skipped: "x",
+				hidden:  "y",
+			},
+			want: `date: "2024-03-15"
+working: true
+workedSeconds: 3600
+punches:
+  - time: "08:00"
+    platform: "WEB"
+  - time: "12:00"
+note: null
+`,
+		},
+		{
+			name: "lista vazia e escalares em lista",
+			in: yamlReport{
+				Ratio: 0.5,
+				Tags:  []string{"a", `b"c`},
+			},
+			want: `date: ""
+working: false
+workedSeconds: 0
+ratio: 0.5
+punches: []
+tags:
+  - "a"
+  - "b\"c"
+note: null
+`,
+		},
+		{
+			name: "struct sem campos",
+			in:   struct{}{},
+			want: "{}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := encodeYAML(&b, tt.in); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("encodeYAML =\n%s\nesperado\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeYAMLUnsupported(t *testing.T) {
+	var b strings.Builder
+	if err := encodeYAML(&b, map[string]int{"a": 1}); err == nil {
+		t.Error("esperado erro para map")
+	}
+}
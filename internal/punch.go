@@ -0,0 +1,415 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+	"github.com/diegodario88/clockwerk/internal/ui"
+)
+
+// Etapas da marcação de ponto (etapa 6 da TUI).
+type punchState int
+
+const (
+	punchRenewing  punchState = iota // renovando o token antes do envio
+	punchSending                     // aguardando a resposta da Senior
+	punchVerifying                   // aceita; conferindo nos eventos
+	punchChecking                    // falha ambígua; conferindo se entrou
+	punchFailed                      // tela de erro, com nova tentativa manual
+)
+
+const (
+	// punchVerifyAttempts e punchVerifyDelay limitam a conferência de uma
+	// marcação, aceita ou após uma falha ambígua, que pode demorar a aparecer
+	// na listagem da Senior.
+	punchVerifyAttempts = 3
+	punchVerifyDelay    = 3 * time.Second
+	// punchClockSlack tolera a diferença entre o relógio local e o da Senior
+	// ao procurar uma marcação nova após uma falha ambígua.
+	punchClockSlack = 2 * time.Minute
+)
+
+// punchFlow acompanha uma marcação, do envio à conferência.
+type punchFlow struct {
+	state     punchState
+	startedAt time.Time
+	// known são os IDs das marcações já conhecidas antes do envio.
+	known    map[string]bool
+	punched  PostClockingMsg
+	attempts int
+	err      error
+	// uncertain indica que a falha ocorreu depois do envio (rede, 5xx), então
+	// a marcação pode ter entrado; uma nova tentativa confere antes.
+	uncertain bool
+}
+
+// punchReceipt é o comprovante exibido no dashboard após uma marcação.
+type punchReceipt struct {
+	at        time.Time
+	confirmed bool
+}
+
+// punchResultMsg é o resultado do envio de uma marcação.
+type punchResultMsg struct {
+	punched PostClockingMsg
+	err     error
+}
+
+// punchVerifyMsg é o resultado da busca de eventos que confere a marcação.
+type punchVerifyMsg struct {
+	events eventMsg
+	err    error
+}
+
+// checkPunch aplica core.CheckPunch às marcações de hoje, com o intervalo
+// mínimo da configuração.
+func checkPunch(msg eventMsg, now time.Time) core.PunchCheck {
+	config, err := core.LoadConfig()
+	if err != nil {
+		log.Printf("Erro ao carregar configuração: %v", err)
+	}
+	return core.CheckPunch(msg.timeTable, todayPunches(msg), now, config.MinPunchInterval())
+}
+
+// summarizePunch descreve uma marcação em now: o que ela significa no dia e o
+// que muda com ela (tempo trabalhado, intervalo, saída prevista e, na saída,
+// o saldo do dia).
+func summarizePunch(msg eventMsg, now time.Time) ui.PunchSummary {
+	punches := todayPunches(msg)
+	after := append(slices.Clone(punches), now)
+	intent := core.PunchIntent(msg.timeTable, len(punches))
+
+	summary := ui.PunchSummary{
+		Intent:       intent,
+		At:           now,
+		BlockReasons: checkPunch(msg, now).Reasons,
+	}
+
+	worked := core.WorkedDuration(after)
+	if intent != core.IntentEntry {
+		summary.Details = append(summary.Details, "Trabalhado até agora: "+core.FormatDuration(worked))
+	}
+
+	switch intent {
+	case core.IntentBreakEnd:
+		summary.Details = append(summary.Details,
+			"Intervalo: "+core.FormatDuration(now.Sub(punches[len(punches)-1])))
+	case core.IntentExit:
+		if expected, ok := core.ExpectedDailyWork(msg.timeTable); ok {
+			summary.Details = append(summary.Details,
+				"Saldo do dia: "+core.FormatSignedDuration(worked-expected))
+		}
+		return summary
+	case core.IntentExtra:
+		return summary
+	}
+
+	if exp, ok := core.ParseTimeTable(msg.timeTable); ok {
+		if predicted, ok := core.PredictExit(exp, after, now); ok {
+			summary.Details = append(summary.Details, "Saída prevista: "+predicted.Format("15:04"))
+		}
+	}
+	return summary
+}
+
+// startPunch inicia a marcação com os eventos atuais como referência.
+func startPunch(m *clockTimer) tea.Cmd {
+	m.step = 6
+	m.punch = punchFlow{
+		state:     punchSending,
+		startedAt: core.Now(),
+		known:     knownClockingIDs(m.eventMsg),
+	}
+	resetRetry(m)
+
+	// Um token perto de expirar é renovado antes da marcação;
+	// dispatchTokenRenewal envia a marcação em seguida.
+	if needsTokenRenewal(m) {
+		m.punch.state = punchRenewing
+		return tea.Batch(renewTokenCmd(m, renewForPunch), m.spinner.Tick)
+	}
+	return tea.Batch(handlePostClockingEvent(m.ctx, m.token, m.eventMsg), m.spinner.Tick)
+}
+
+// sendPunch envia a marcação, após a confirmação ou uma renovação de token.
+func sendPunch(m *clockTimer) tea.Cmd {
+	m.punch.state = punchSending
+	return handlePostClockingEvent(m.ctx, m.token, m.eventMsg)
+}
+
+func verifyPunchCmd(ctx context.Context, token string, delay time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return punchVerifyMsg{err: ctx.Err()}
+			}
+		}
+		// Com o daemon, a última busca dele pode ser anterior à marcação.
+		events, err := refreshEventMsg(ctx, token)
+		if err != nil {
+			return punchVerifyMsg{err: err}
+		}
+		return punchVerifyMsg{events: events}
+	}
+}
+
+// punchMayHaveLanded informa se a marcação pode ter sido registrada apesar da
+// falha: a requisição saiu, para a Senior ou para o daemon, mas a resposta não
+// chegou ou não foi entendida.
+func punchMayHaveLanded(err error) bool {
+	var serverErr *senior.ErrServer
+	if errors.As(err, &serverErr) {
+		return serverErr.Status >= 500
+	}
+	return errors.Is(err, senior.ErrNetwork) || errors.Is(err, senior.ErrDecode) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, core.ErrDaemonNoResponse)
+}
+
+func knownClockingIDs(msg eventMsg) map[string]bool {
+	known := make(map[string]bool)
+	for _, clockings := range msg.clocking {
+		for _, c := range clockings {
+			known[c.id] = true
+		}
+	}
+	return known
+}
+
+// findPunch procura a marcação em msg: pela data e hora devolvidas pela
+// Senior ou, sem elas, por uma marcação nova feita desde since.
+func findPunch(msg eventMsg, punched PostClockingMsg, known map[string]bool, since time.Time) (clockingMsg, bool) {
+	if punched.dateEvent != "" && punched.timeEvent != "" {
+		for _, c := range msg.clocking[punched.dateEvent] {
+			if sameClockTime(c.time, punched.timeEvent) {
+				return c, true
+			}
+		}
+		return clockingMsg{}, false
+	}
+
+	for _, clockings := range msg.clocking {
+		for _, c := range clockings {
+			if !known[c.id] && !c.eventTime.Before(since.Add(-punchClockSlack)) {
+				return c, true
+			}
+		}
+	}
+	return clockingMsg{}, false
+}
+
+// sameClockTime compara horários da Senior até os segundos, ignorando a
+// fração ("08:01:23" e "08:01:23.000").
+func sameClockTime(a, b string) bool {
+	a, _, _ = strings.Cut(a, ".")
+	b, _, _ = strings.Cut(b, ".")
+	return a == b
+}
+
+// punchedTime devolve o horário informado pela Senior para a marcação, no
+// fuso em que ela foi enviada (como o eventTime das marcações), ou, sem ele, o
+// do envio.
+func punchedTime(p punchFlow) time.Time {
+	t, err := time.Parse(core.TimeLayout,
+		fmt.Sprintf("%s %s %s", p.punched.dateEvent, p.punched.timeEvent, p.punched.timeZone))
+	if err != nil {
+		return p.startedAt
+	}
+	return t
+}
+
+// describePunchReceipt monta o comprovante, ex.: "ponto registrado às 08:01".
+func describePunchReceipt(r punchReceipt) string {
+	if r.confirmed {
+		return "✔ ponto registrado às " + r.at.Format("15:04")
+	}
+	return "⚠ ponto enviado às " + r.at.Format("15:04") + ", ainda não aparece na Senior"
+}
+
+// finishPunch volta ao dashboard com os eventos conferidos e o comprovante.
+func finishPunch(m *clockTimer, events eventMsg, receipt punchReceipt) tea.Cmd {
+	m.step = 5
+	m.punchReceipt = &receipt
+	m.hasAuthRecover = false
+	applyEventMsg(m, events)
+	m.fetchedAt = time.Now()
+	m.stale = false
+	m.refreshing = false
+	m.failedMsg = FailedMsg{}
+
+	if receipt.confirmed {
+		go handleDesktopNotification("Clockwerk", "Ponto registrado às "+receipt.at.Format("15:04")+".", "normal")
+	}
+	return tea.Batch(scheduleTick(m), scheduleRefresh(m))
+}
+
+// dispatchPunch conduz a etapa 6: envio, conferência e a tela de erro.
+func dispatchPunch(msg tea.Msg, m *clockTimer) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+
+	switch msg := msg.(type) {
+	case tickMsg:
+		// Mantém o relógio andando enquanto a marcação está em curso.
+		m.tickScheduled = false
+		updateElapsed(m)
+		return m, scheduleTick(m)
+
+	case refreshTickMsg:
+		// A conferência da marcação já busca os eventos; o ciclo de refresh é
+		// reagendado na volta ao dashboard.
+		m.refreshScheduled = false
+		return m, nil
+
+	case auditsMsg:
+		m.audits = msg.audits
+		return m, nil
+
+	case eventMsg, FailedMsg:
+		// Resultado de um refresh iniciado antes da marcação.
+		m.refreshing = false
+		return m, nil
+
+	case LoginMsg:
+		// Login refeito após a Senior recusar o token da marcação, que então
+		// não foi registrada e pode ser reenviada.
+		m.token = msg.token
+		saveRenewedToken(m, core.RenewalExpired)
+		return m, tea.Batch(sendPunch(m), m.spinner.Tick)
+
+	case punchResultMsg:
+		return handlePunchResult(msg, m)
+
+	case punchVerifyMsg:
+		return handlePunchVerify(msg, m)
+
+	case tea.KeyMsg:
+		if m.punch.state != punchFailed {
+			return m, cmd
+		}
+		switch {
+		case key.Matches(msg, m.keys.Retry):
+			m.punch.err = nil
+			m.punch.attempts = 0
+			if m.punch.uncertain {
+				m.punch.state = punchChecking
+				return m, tea.Batch(verifyPunchCmd(m.ctx, m.token, 0), m.spinner.Tick)
+			}
+			m.punch.startedAt = core.Now()
+			return m, tea.Batch(sendPunch(m), m.spinner.Tick)
+		case key.Matches(msg, m.keys.Exit):
+			m.step = 5
+			return m, tea.Batch(scheduleTick(m), scheduleRefresh(m))
+		}
+	}
+
+	return m, cmd
+}
+
+func handlePunchResult(msg punchResultMsg, m *clockTimer) (tea.Model, tea.Cmd) {
+	if msg.err == nil {
+		m.punch.punched = msg.punched
+		m.punch.state = punchVerifying
+		return m, tea.Batch(verifyPunchCmd(m.ctx, m.token, 0), m.spinner.Tick)
+	}
+
+	switch {
+	case errors.Is(msg.err, senior.ErrTokenExpired) && m.sso:
+		return promptToken(m)
+	case errors.Is(msg.err, senior.ErrTokenExpired) && !m.hasAuthRecover && m.password != "":
+		m.hasAuthRecover = true
+		// A falha do login vira falha da marcação, para não ser confundida
+		// com a de um refresh em segundo plano.
+		relogin := handleAuthentication(m.ctx, fmt.Sprintf("%s@%s", m.cpf, m.domain), m.password)
+		return m, tea.Batch(func() tea.Msg {
+			msg := relogin()
+			if failed, ok := msg.(FailedMsg); ok {
+				return punchResultMsg{err: failed.err}
+			}
+			return msg
+		}, m.spinner.Tick)
+	case punchMayHaveLanded(msg.err):
+		log.Printf("Marcação sem resposta, conferindo se foi registrada: %v", msg.err)
+		m.punch.err = msg.err
+		m.punch.attempts = 0
+		m.punch.uncertain = true
+		m.punch.state = punchChecking
+		return m, tea.Batch(verifyPunchCmd(m.ctx, m.token, 0), m.spinner.Tick)
+	default:
+		m.punch.err = msg.err
+		m.punch.uncertain = false
+		m.punch.state = punchFailed
+		return m, nil
+	}
+}
+
+func handlePunchVerify(msg punchVerifyMsg, m *clockTimer) (tea.Model, tea.Cmd) {
+	switch m.punch.state {
+	case punchVerifying:
+		if msg.err != nil {
+			// A Senior aceitou a marcação; só a conferência falhou. O
+			// dashboard fica com os dados anteriores até o próximo refresh.
+			log.Printf("Erro ao conferir marcação: %v", msg.err)
+			m.step = 5
+			m.punchReceipt = &punchReceipt{at: punchedTime(m.punch)}
+			m.stale = true
+			if core.Retryable(msg.err) {
+				return m, tea.Batch(scheduleTick(m), scheduleRetry(m, msg.err))
+			}
+			return m, tea.Batch(scheduleTick(m), scheduleRefresh(m))
+		}
+
+		if c, ok := findPunch(msg.events, m.punch.punched, m.punch.known, m.punch.startedAt); ok {
+			return m, finishPunch(m, msg.events, punchReceipt{at: c.eventTime, confirmed: true})
+		}
+		m.punch.attempts++
+		if m.punch.attempts < punchVerifyAttempts {
+			return m, tea.Batch(verifyPunchCmd(m.ctx, m.token, punchVerifyDelay), m.spinner.Tick)
+		}
+		return m, finishPunch(m, msg.events, punchReceipt{at: punchedTime(m.punch)})
+
+	case punchChecking:
+		// Como na conferência de uma marcação aceita, a listagem pode
+		// demorar: só depois de punchVerifyAttempts buscas sem a marcação ela
+		// é dada como não registrada e a nova tentativa volta a enviá-la.
+		if msg.err != nil {
+			// Não dá para saber se a marcação entrou: a nova tentativa
+			// confere de novo antes de reenviar.
+			if m.punch.err == nil {
+				m.punch.err = msg.err
+			}
+			m.punch.uncertain = true
+			m.punch.state = punchFailed
+			return m, nil
+		}
+
+		if c, ok := findPunch(msg.events, PostClockingMsg{}, m.punch.known, m.punch.startedAt); ok {
+			return m, finishPunch(m, msg.events, punchReceipt{at: c.eventTime, confirmed: true})
+		}
+		m.punch.attempts++
+		if m.punch.attempts < punchVerifyAttempts {
+			return m, tea.Batch(verifyPunchCmd(m.ctx, m.token, punchVerifyDelay), m.spinner.Tick)
+		}
+		applyEventMsg(m, msg.events)
+		m.fetchedAt = time.Now()
+		m.stale = false
+		m.punch.uncertain = false
+		m.punch.state = punchFailed
+		if m.punch.err == nil {
+			m.punch.err = fmt.Errorf("a marcação não aparece na Senior")
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
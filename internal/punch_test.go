@@ -0,0 +1,304 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+// fakeDaemon atende o socket do daemon, em um diretório temporário, com
+// respond. Devolve os métodos pedidos até o momento.
+func fakeDaemon(t *testing.T, respond func(core.IPCRequest) core.IPCResponse) func() []string {
+	t.Helper()
+	// O caminho de um socket Unix é curto; t.TempDir() pode passar do limite.
+	dir, err := os.MkdirTemp("", "cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	listener, err := net.Listen("unix", core.GetSocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	var methods []string
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			var req core.IPCRequest
+			if err := json.NewDecoder(conn).Decode(&req); err == nil {
+				mu.Lock()
+				methods = append(methods, req.Method)
+				mu.Unlock()
+				json.NewEncoder(conn).Encode(respond(req))
+			}
+			conn.Close()
+		}
+	}()
+
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), methods...)
+	}
+}
+
+func punchTestEvent(id, date, clock string) core.ClockingEvent {
+	return core.ClockingEvent{ID: id, DateEvent: date, TimeEvent: clock, TimeZone: "-03:00"}
+}
+
+func TestSessionFindPunchRefreshesDaemon(t *testing.T) {
+	stale := []core.ClockingEvent{punchTestEvent("a", "2024-03-15", "08:00:00")}
+	fresh := append(append([]core.ClockingEvent(nil), stale...), punchTestEvent("b", "2024-03-15", "12:01:23"))
+
+	methods := fakeDaemon(t, func(req core.IPCRequest) core.IPCResponse {
+		// A última busca do daemon ainda não tem a marcação; só uma nova
+		// busca a encontra.
+		if req.Method == core.IPCMethodRefresh {
+			return core.IPCResponse{Events: fresh}
+		}
+		return core.IPCResponse{Events: stale}
+	})
+
+	before, err := newEventMsg(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &session{ctx: context.Background(), attached: true}
+	punched := PostClockingMsg{dateEvent: "2024-03-15", timeEvent: "12:01:23.000", timeZone: "-03:00"}
+
+	c, found, err := s.findPunch(before, punched, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || c.id != "b" {
+		t.Errorf("findPunch = %q, %v; esperado a marcação b", c.id, found)
+	}
+	for _, method := range methods() {
+		if method != core.IPCMethodRefresh {
+			t.Errorf("conferência usou %q, esperado %q", method, core.IPCMethodRefresh)
+		}
+	}
+}
+
+func TestPunchMayHaveLanded(t *testing.T) {
+	deadline := fmt.Errorf("%w: read unix: %w", core.ErrDaemonNoResponse, os.ErrDeadlineExceeded)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "rede", err: fmt.Errorf("%w: connection reset", senior.ErrNetwork), want: true},
+		{name: "resposta ilegível", err: fmt.Errorf("%w: EOF", senior.ErrDecode), want: true},
+		{name: "erro 502", err: &senior.ErrServer{Status: 502}, want: true},
+		{name: "timeout HTTP", err: context.DeadlineExceeded, want: true},
+		{name: "prazo do daemon esgotado", err: deadline, want: true},
+		{name: "daemon fechou a conexão", err: fmt.Errorf("%w: EOF", core.ErrDaemonNoResponse), want: true},
+		{name: "erro 400", err: &senior.ErrServer{Status: 400}},
+		{name: "token recusado", err: senior.ErrTokenExpired},
+		{name: "sem daemon", err: core.ErrDaemonUnavailable},
+		{name: "cancelado", err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := punchMayHaveLanded(tt.err); got != tt.want {
+				t.Errorf("punchMayHaveLanded = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPunchMayHaveLandedDaemonTimeout(t *testing.T) {
+	// Um daemon que recebe a marcação e não responde dentro do prazo.
+	dir, err := os.MkdirTemp("", "cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	listener, err := net.Listen("unix", core.GetSocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		var req core.IPCRequest
+		json.NewDecoder(conn).Decode(&req)
+		conn.Close()
+	}()
+
+	_, err = postClockingEvent(context.Background(), "", eventMsg{})
+	if !errors.Is(err, core.ErrDaemonNoResponse) || !punchMayHaveLanded(err) {
+		t.Errorf("postClockingEvent = %v; esperado uma marcação possivelmente registrada", err)
+	}
+}
+
+func TestPunchedTime(t *testing.T) {
+	started := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		punched PostClockingMsg
+		want    time.Time
+	}{
+		{
+			name:    "fuso da marcação",
+			punched: PostClockingMsg{dateEvent: "2024-03-15", timeEvent: "12:01:23", timeZone: "-03:00"},
+			want:    time.Date(2024, 3, 15, 15, 1, 23, 0, time.UTC),
+		},
+		{
+			name:    "fração de segundo",
+			punched: PostClockingMsg{dateEvent: "2024-03-15", timeEvent: "12:01:23.000", timeZone: "-03:00"},
+			want:    time.Date(2024, 3, 15, 15, 1, 23, 0, time.UTC),
+		},
+		{
+			name:    "outro fuso",
+			punched: PostClockingMsg{dateEvent: "2024-03-15", timeEvent: "11:01:23", timeZone: "-04:00"},
+			want:    time.Date(2024, 3, 15, 15, 1, 23, 0, time.UTC),
+		},
+		{name: "sem resposta da Senior", want: started},
+		{
+			name:    "sem fuso",
+			punched: PostClockingMsg{dateEvent: "2024-03-15", timeEvent: "12:01:23"},
+			want:    started,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := punchedTime(punchFlow{punched: tt.punched, startedAt: started})
+			if !got.Equal(tt.want) {
+				t.Errorf("punchedTime = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizePunch(t *testing.T) {
+	// Sem configuração: vale o intervalo mínimo padrão de 2 min.
+	t.Setenv("CLOCKWERK_CONFIG", t.TempDir()+"/config.json")
+
+	const timeTable = "08:00-12:00-13:00-17:00"
+	now := core.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	today := func(timeTable string, punches ...time.Time) eventMsg {
+		clockings := make([]clockingMsg, len(punches))
+		for i, punch := range punches {
+			clockings[i] = clockingMsg{id: fmt.Sprint(i), eventTime: punch}
+		}
+		return eventMsg{timeTable: timeTable, clocking: map[string][]clockingMsg{core.TodayKey(): clockings}}
+	}
+
+	tests := []struct {
+		name    string
+		msg     eventMsg
+		now     time.Time
+		intent  string
+		details []string
+		blocked []string
+	}{
+		{
+			name:    "entrada",
+			msg:     today(timeTable),
+			now:     at(7, 58),
+			intent:  core.IntentEntry,
+			details: []string{"Saída prevista: 16:58"},
+		},
+		{
+			name:    "saída para o intervalo",
+			msg:     today(timeTable, at(8, 0)),
+			now:     at(12, 0),
+			intent:  core.IntentBreakStart,
+			details: []string{"Trabalhado até agora: 4h00m", "Saída prevista: 17:00"},
+		},
+		{
+			name:    "retorno do intervalo",
+			msg:     today(timeTable, at(8, 0), at(12, 0)),
+			now:     at(13, 5),
+			intent:  core.IntentBreakEnd,
+			details: []string{"Trabalhado até agora: 4h00m", "Intervalo: 1h05m", "Saída prevista: 17:05"},
+		},
+		{
+			name:    "saída com saldo",
+			msg:     today(timeTable, at(8, 0), at(12, 0), at(13, 5)),
+			now:     at(17, 10),
+			intent:  core.IntentExit,
+			details: []string{"Trabalhado até agora: 8h05m", "Saldo do dia: +0h05m"},
+		},
+		{
+			name:    "saída antes da hora",
+			msg:     today(timeTable, at(8, 0), at(12, 0), at(13, 0)),
+			now:     at(16, 30),
+			intent:  core.IntentExit,
+			details: []string{"Trabalhado até agora: 7h30m", "Saldo do dia: -0h30m"},
+		},
+		{
+			name:    "além do expediente",
+			msg:     today(timeTable, at(8, 0), at(12, 0), at(13, 5), at(17, 10)),
+			now:     at(18, 0),
+			intent:  core.IntentExtra,
+			details: []string{"Trabalhado até agora: 8h05m"},
+			blocked: []string{"esta seria a 5ª marcação de hoje, mas o expediente (08:00-12:00-13:00-17:00) prevê 4"},
+		},
+		{
+			name:    "clique duplo",
+			msg:     today(timeTable, at(8, 0)),
+			now:     at(8, 1),
+			intent:  core.IntentBreakStart,
+			details: []string{"Trabalhado até agora: 0h01m", "Saída prevista: 17:00"},
+			blocked: []string{"a última marcação foi às 08:00:00, há 1m; o intervalo mínimo é de 2m"},
+		},
+		{
+			name:    "sem expediente",
+			msg:     today("", at(8, 0)),
+			now:     at(12, 0),
+			intent:  core.IntentExit,
+			details: []string{"Trabalhado até agora: 4h00m"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizePunch(tt.msg, tt.now)
+			if got.Intent != tt.intent {
+				t.Errorf("Intent = %q, esperado %q", got.Intent, tt.intent)
+			}
+			if !got.At.Equal(tt.now) {
+				t.Errorf("At = %v, esperado %v", got.At, tt.now)
+			}
+			if !slices.Equal(got.Details, tt.details) {
+				t.Errorf("Details = %q, esperado %q", got.Details, tt.details)
+			}
+			if !slices.Equal(got.BlockReasons, tt.blocked) {
+				t.Errorf("BlockReasons = %q, esperado %q", got.BlockReasons, tt.blocked)
+			}
+		})
+	}
+}
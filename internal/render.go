@@ -90,6 +90,50 @@ func renderPasswordStep(m *clockTimer) string {
 	return b.String()
 }
 
+func renderTokenStep(m *clockTimer) string {
+	var b strings.Builder
+
+	b.WriteString(
+		lipgloss.NewStyle().Bold(true).Render("Autenticação - Etapa 2/3: Token SSO") + "\n\n",
+	)
+
+	if m.tokenExpired {
+		b.WriteString(
+			lipgloss.NewStyle().
+				Foreground(lipgloss.Color(core.AmberFlare)).
+				Render("⚠ Seu token expirou. Cole um novo para continuar.") + "\n\n",
+		)
+	}
+
+	b.WriteString(
+		"Domínio: " +
+			lipgloss.NewStyle().Italic(true).Render(m.domain) + "\n",
+	)
+
+	b.WriteString(
+		"CPF:     " +
+			lipgloss.NewStyle().Italic(true).Render(m.cpf) + "\n\n",
+	)
+
+	b.WriteString(
+		lipgloss.NewStyle().
+			Italic(true).
+			Render("Com a Senior X aberta no navegador, copie o cabeçalho Authorization de uma\n"+
+				"requisição à plataforma (ferramentas do desenvolvedor → Rede).") + "\n\n",
+	)
+
+	b.WriteString(m.tokenForm.View() + "\n\n")
+
+	b.WriteString(
+		lipgloss.NewStyle().
+			Width(core.AppWidth).
+			AlignHorizontal(lipgloss.Center).
+			Render(m.paginator.View()),
+	)
+
+	return b.String()
+}
+
 func renderKeepStep(m *clockTimer) string {
 	var b strings.Builder
 
@@ -132,7 +176,7 @@ func renderAuthStep(m *clockTimer) string {
 
 	limitedHelp := customHelp{keys.Retry, keys.Quit}
 
-	if m.failedMsg.error != "" {
+	if m.failedMsg.err != nil {
 		b.WriteString(
 			lipgloss.NewStyle().
 				Bold(true).
@@ -153,10 +197,20 @@ func renderAuthStep(m *clockTimer) string {
 				Width(core.AppWidth).
 				AlignHorizontal(lipgloss.Center).
 				Italic(true).
-				Render(fmt.Sprintf("Mensagem: %s", m.failedMsg.error)) +
+				Render(fmt.Sprintf("Mensagem: %s", describeError(m.failedMsg.err))) +
 				"\n\n",
 		)
 
+		if retryPending(m) {
+			b.WriteString(
+				lipgloss.NewStyle().
+					Width(core.AppWidth).
+					AlignHorizontal(lipgloss.Center).
+					Foreground(lipgloss.Color(core.AmberFlare)).
+					Render("⟳ "+retryLabel(m)) + "\n\n",
+			)
+		}
+
 		b.WriteString(
 			lipgloss.NewStyle().
 				Width(core.AppWidth).
@@ -189,7 +243,7 @@ func renderEventsStep(m *clockTimer) string {
 
 	limitedHelp := customHelp{keys.Retry, keys.Quit}
 
-	if m.failedMsg.error != "" {
+	if m.failedMsg.err != nil {
 		b.WriteString(
 			lipgloss.NewStyle().
 				Bold(true).
@@ -206,10 +260,18 @@ func renderEventsStep(m *clockTimer) string {
 		b.WriteString(
 			lipgloss.NewStyle().
 				Italic(true).
-				Render(fmt.Sprintf("Mensagem: %s", m.failedMsg.error)) +
+				Render(fmt.Sprintf("Mensagem: %s", describeError(m.failedMsg.err))) +
 				"\n\n",
 		)
 
+		if retryPending(m) {
+			b.WriteString(
+				lipgloss.NewStyle().
+					Foreground(lipgloss.Color(core.AmberFlare)).
+					Render("⟳ "+retryLabel(m)) + "\n\n",
+			)
+		}
+
 		b.WriteString(
 			lipgloss.NewStyle().
 				PaddingLeft(2).
@@ -239,7 +301,7 @@ func renderEventsStep(m *clockTimer) string {
 func renderDashboardStep(m *clockTimer) string {
 	var b strings.Builder
 
-	now := time.Now()
+	now := core.Now()
 	h := int(m.elapsed.Hours())
 	mm := int(m.elapsed.Minutes()) % 60
 	ss := int(m.elapsed.Seconds()) % 60
@@ -267,14 +329,19 @@ func renderDashboardStep(m *clockTimer) string {
 	left := " Registro de Ponto - Clockwerk"
 
 	var right string
+	if m.stale && !m.fetchedAt.IsZero() {
+		right = fmt.Sprintf("⚠ dados de há %s · ", core.FormatDuration(time.Since(m.fetchedAt)))
+	}
 	if m.refreshing {
-		right = "⟳ atualizando… "
+		right += "⟳ atualizando… "
+	} else if retryPending(m) {
+		right += retryLabel(m) + " "
 	} else if !m.nextRefresh.IsZero() {
 		remaining := time.Until(m.nextRefresh)
 		if remaining < 0 {
 			remaining = 0
 		}
-		right = fmt.Sprintf(
+		right += fmt.Sprintf(
 			"↻ próx. atualização %02d:%02d ",
 			int(remaining.Minutes()),
 			int(remaining.Seconds())%60,
@@ -321,20 +388,30 @@ func renderDashboardStep(m *clockTimer) string {
 			"Expediente:     " + m.eventMsg.timeTable,
 		}
 
-		if exp, ok := core.ParseTimeTable(m.eventMsg.timeTable); ok {
-			var punches []time.Time
-			for _, event := range m.eventMsg.clocking[core.TodayKey] {
-				punches = append(punches, event.eventTime)
-			}
-			if predicted, ok := core.PredictExit(exp, punches, now); ok {
-				lines = append(lines, "Saída prevista: "+predicted.Format("15:04"))
-			}
+		if predicted, ok := predictTodayExit(m.eventMsg, now); ok {
+			lines = append(lines, "Saída prevista: "+predicted.Format("15:04"))
 		}
 
 		lines = append(lines, "Registros:      "+strconv.Itoa(m.punchCount))
 
+		if m.punchReceipt != nil {
+			color := core.MintGreen
+			if !m.punchReceipt.confirmed {
+				color = core.SunflowerYellow
+			}
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color(color)).
+				Render(describePunchReceipt(*m.punchReceipt)))
+		}
+
+		if warning, ok := core.ClockSkewWarning(); ok {
+			lines = append(lines, lipgloss.NewStyle().
+				Foreground(lipgloss.Color(core.SunflowerYellow)).
+				Render("⚠ "+warning+"; horários pelo relógio da Senior"))
+		}
+
 		contentBuilder.WriteString(strings.Join(lines, "\n"))
-		maybeTodayClock, exists := m.eventMsg.clocking[core.TodayKey]
+		maybeTodayClock, exists := m.eventMsg.clocking[m.today]
 
 		if exists {
 			t := tree.Root(".")
@@ -404,15 +481,46 @@ func renderDashboardStep(m *clockTimer) string {
 			lipgloss.NewStyle().
 				Width(core.AppWidth).
 				AlignHorizontal(lipgloss.Center).
-				Render(subTabsLine.String()) + "\n\n",
+				Render(subTabsLine.String()) + "\n",
 		)
 
+		month := historyMonth(now, m.historyOffset)
 		var selected []string
 		if m.historyView == 0 {
-			selected = selectWeekDates(m.eventMsg.clocking)
+			selected = selectWeekDates(m.eventMsg.clocking, m.historyOffset)
 		} else {
-			selected = selectMonthDates(m.eventMsg.clocking, now)
+			selected = selectMonthDates(m.eventMsg.clocking, month)
+		}
+
+		periodLine := "◀ " + historyPeriodLabel(m.historyView, selected, month)
+		if m.historyOffset > 0 {
+			periodLine += " ▶"
+		}
+		contentBuilder.WriteString(
+			lipgloss.NewStyle().
+				Width(core.AppWidth).
+				AlignHorizontal(lipgloss.Center).
+				Foreground(lipgloss.Color(core.ClockWerkColor)).
+				Render(periodLine) + "\n",
+		)
+		if m.historyLoading {
+			contentBuilder.WriteString(
+				lipgloss.NewStyle().
+					Width(core.AppWidth).
+					AlignHorizontal(lipgloss.Center).
+					Italic(true).
+					Render("Buscando o período na Senior...") + "\n",
+			)
+		} else if m.historyError != "" {
+			contentBuilder.WriteString(
+				lipgloss.NewStyle().
+					Width(core.AppWidth).
+					AlignHorizontal(lipgloss.Center).
+					Foreground(lipgloss.Color(core.LavaRed)).
+					Render("Não foi possível buscar o período: "+m.historyError) + "\n",
+			)
 		}
+		contentBuilder.WriteString("\n")
 
 		var totalWorked, totalBalance time.Duration
 		hasIncomplete := false
@@ -443,9 +551,9 @@ func renderDashboardStep(m *clockTimer) string {
 			contentBuilder.WriteString(renderWeekChart(m.eventMsg.clocking, m.eventMsg.timeTable, selected))
 			contentBuilder.WriteString("\n")
 		} else {
-			contentBuilder.WriteString(renderMonthTable(m.eventMsg.clocking, m.eventMsg.timeTable, selected))
+			contentBuilder.WriteString(renderMonthTable(m.eventMsg.clocking, m.eventMsg.timeTable, m.audits, selected))
 			contentBuilder.WriteString("\n")
-			if note := monthCoverageNote(m.eventMsg.clocking, now); note != "" {
+			if note := monthCoverageNote(m.eventMsg.clocking, month); note != "" {
 				contentBuilder.WriteString(
 					lipgloss.NewStyle().
 						Italic(true).
@@ -475,12 +583,36 @@ func renderDashboardStep(m *clockTimer) string {
 					Render("⚠ Dias com marcações faltando não entram no saldo (aguardando ajuste).") + "\n",
 			)
 		}
+
+		// Ajustes feitos pelo RH em marcações passadas (os mais recentes).
+		if audits := recentAudits(m.audits, now); len(audits) > 0 {
+			contentBuilder.WriteString(
+				lipgloss.NewStyle().
+					Bold(true).
+					Foreground(lipgloss.Color(core.SunflowerYellow)).
+					Render("✎ Ajustes detectados na Senior:") + "\n",
+			)
+			for i, a := range audits {
+				if i == 3 {
+					contentBuilder.WriteString(
+						lipgloss.NewStyle().Italic(true).
+							Render(fmt.Sprintf("  … e mais %d", len(audits)-3)) + "\n",
+					)
+					break
+				}
+				contentBuilder.WriteString(
+					fmt.Sprintf("  %s (detectado em %s)\n", describeAudit(a), a.DetectedAt.Format("02/01 15:04")),
+				)
+			}
+		}
 		contentBuilder.WriteString("\n")
 
 		historyHelp := customHelp{
 			keys.MoveBack,
 			keys.MoveForward,
 			keys.ToggleHistoryView,
+			keys.PreviousPeriod,
+			keys.NextPeriod,
 			keys.Exit,
 			keys.Quit,
 		}
@@ -515,6 +647,7 @@ func renderDashboardStep(m *clockTimer) string {
 		contentBuilder.WriteString(fmt.Sprintf("Go:            %s\n", goVersion))
 		contentBuilder.WriteString(fmt.Sprintf("Sistema:       %s\n", osInfo))
 		contentBuilder.WriteString(fmt.Sprintf("Desktop:       %s\n", desktop))
+		contentBuilder.WriteString(fmt.Sprintf("Sessão:        %s\n", describeTokenSession(m)))
 		contentBuilder.WriteString(fmt.Sprintf("CPU Núcleos:   %d\n", runtime.NumCPU()))
 		contentBuilder.WriteString(fmt.Sprintf("Goroutines:    %d\n", runtime.NumGoroutine()))
 		contentBuilder.WriteString(
@@ -549,7 +682,18 @@ func renderDashboardStep(m *clockTimer) string {
 func renderPunchStep(m *clockTimer) string {
 	var b strings.Builder
 
-	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Registrando evento ponto...") + "\n\n")
+	if m.punch.state == punchFailed {
+		return renderPunchFailed(m)
+	}
+
+	title := "Registrando evento ponto..."
+	switch m.punch.state {
+	case punchVerifying:
+		title = "Conferindo a marcação na Senior..."
+	case punchChecking:
+		title = "Sem resposta da Senior, conferindo se o ponto foi registrado..."
+	}
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
 
 	s := lipgloss.NewStyle().Render(m.spinner.View())
 
@@ -566,6 +710,53 @@ func renderPunchStep(m *clockTimer) string {
 	return b.String()
 }
 
+func renderPunchFailed(m *clockTimer) string {
+	var b strings.Builder
+
+	b.WriteString(
+		lipgloss.NewStyle().
+			Bold(true).
+			Render("Ops.. Falha ao registrar o ponto") +
+			"\n\n",
+	)
+
+	b.WriteString(
+		lipgloss.NewStyle().
+			Italic(true).
+			Render(fmt.Sprintf("Mensagem: %s", describeError(m.punch.err))) +
+			"\n\n",
+	)
+
+	outcome := "O ponto não foi registrado. <r> envia a marcação de novo."
+	if m.punch.uncertain {
+		outcome = "Não foi possível confirmar se o ponto entrou. <r> confere na Senior antes de reenviar."
+	}
+	b.WriteString(
+		lipgloss.NewStyle().
+			Foreground(lipgloss.Color(core.AmberFlare)).
+			Render(outcome) + "\n\n",
+	)
+
+	b.WriteString(
+		lipgloss.NewStyle().
+			PaddingLeft(2).
+			Blink(true).
+			Foreground(lipgloss.Color(core.ClockWerkColor)).
+			Render(
+				"¯\\_(ツ)_/¯",
+			) + "\n\n",
+	)
+
+	b.WriteString(
+		lipgloss.NewStyle().
+			Width(core.AppWidth).
+			AlignHorizontal(lipgloss.Center).
+			Render(m.help.View(customHelp{keys.Retry, keys.Exit, keys.Quit})),
+	)
+
+	return b.String()
+}
+
 type historyDayBalance struct {
 	when     time.Time
 	worked   time.Duration
@@ -597,7 +788,7 @@ func computeDayBalance(dateKey string, clockings []clockingMsg, timeTable string
 		db.balance = db.worked - exp
 
 		// Hoje pode estar em andamento: ignora saldo negativo (não é débito real).
-		if dateKey == core.TodayKey && db.balance < 0 {
+		if dateKey == core.TodayKey() && db.balance < 0 {
 			db.hasExp = false
 			db.balance = 0
 		}
@@ -614,8 +805,9 @@ func parseDateKey(key string) (time.Time, bool) {
 	return t, true
 }
 
-// selectWeekDates: últimos 5 dias úteis com marcações, do mais recente ao mais antigo.
-func selectWeekDates(clocking map[string][]clockingMsg) []string {
+// selectWeekDates: 5 dias úteis com marcações, do mais recente ao mais antigo,
+// pulando os offset blocos de 5 dias mais recentes.
+func selectWeekDates(clocking map[string][]clockingMsg, offset int) []string {
 	var dates []string
 	for date := range clocking {
 		if hideTodayWithoutLunch(date, clocking[date]) {
@@ -631,13 +823,45 @@ func selectWeekDates(clocking map[string][]clockingMsg) []string {
 	}
 
 	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
-	if len(dates) > 5 {
-		dates = dates[:5]
+	start := offset * 5
+	if start >= len(dates) {
+		return nil
 	}
-	return dates
+	return dates[start:min(start+5, len(dates))]
+}
+
+// historyMonth devolve uma referência dentro do mês exibido com deslocamento
+// offset (0 = mês atual). Para meses passados é o último dia do mês, o que
+// mantém monthCoverageNote coerente.
+func historyMonth(now time.Time, offset int) time.Time {
+	if offset == 0 {
+		return now
+	}
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return first.AddDate(0, 1-offset, -1)
 }
 
-// selectMonthDates: dias do mês calendário de `now` com marcações, em ordem crescente.
+var monthNames = [...]string{
+	"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+	"julho", "agosto", "setembro", "outubro", "novembro", "dezembro",
+}
+
+// historyPeriodLabel descreve o período exibido, ex.: "06/10 a 10/10" ou
+// "outubro de 2026".
+func historyPeriodLabel(view int, selected []string, month time.Time) string {
+	if view == 1 {
+		return fmt.Sprintf("%s de %d", monthNames[month.Month()-1], month.Year())
+	}
+	if len(selected) == 0 {
+		return "sem marcações"
+	}
+	return fmt.Sprintf("%s a %s",
+		formatShortDateKey(selected[len(selected)-1]),
+		formatShortDateKey(selected[0]))
+}
+
+// selectMonthDates: dias do mês calendário de `now` com marcações, em ordem
+// crescente (now pode ser qualquer dia do mês desejado).
 func selectMonthDates(clocking map[string][]clockingMsg, now time.Time) []string {
 	var dates []string
 	for date := range clocking {
@@ -659,7 +883,7 @@ func selectMonthDates(clocking map[string][]clockingMsg, now time.Time) []string
 
 // hideTodayWithoutLunch oculta o dia de hoje enquanto tiver menos de 2 marcações.
 func hideTodayWithoutLunch(date string, clockings []clockingMsg) bool {
-	return date == core.TodayKey && len(clockings) < 2
+	return date == core.TodayKey() && len(clockings) < 2
 }
 
 // renderWeekChart desenha o gráfico da semana com o saldo do dia acima de cada barra.
@@ -749,7 +973,8 @@ func centerPlainText(s string, width int) string {
 }
 
 // renderMonthTable monta a tabela Data | Trabalhado | Saldo | Marcações do mês.
-func renderMonthTable(clocking map[string][]clockingMsg, timeTable string, dates []string) string {
+// Dias com ajustes detectados recebem "✎" ao lado das marcações.
+func renderMonthTable(clocking map[string][]clockingMsg, timeTable string, audits []core.AuditEntry, dates []string) string {
 	var b strings.Builder
 
 	const (
@@ -788,20 +1013,19 @@ func renderMonthTable(clocking map[string][]clockingMsg, timeTable string, dates
 			col(wWorked).Render(core.FormatDuration(db.worked)) +
 			saldoStyle.Render(saldo) +
 			lipgloss.NewStyle().Render(strings.Join(marks, " "))
+		if len(auditsForDate(audits, date)) > 0 {
+			row += lipgloss.NewStyle().Foreground(lipgloss.Color(core.SunflowerYellow)).Render(" ✎")
+		}
 		b.WriteString(row + "\n")
 	}
 
 	return b.String()
 }
 
-// monthCoverageNote sinaliza quando os dados não cobrem o início do mês (limite da API).
+// monthCoverageNote sinaliza quando os dados (API + livro local) não cobrem o
+// início do mês.
 func monthCoverageNote(clocking map[string][]clockingMsg, now time.Time) string {
-	earliest := ""
-	for date := range clocking {
-		if earliest == "" || date < earliest {
-			earliest = date
-		}
-	}
+	earliest := earliestDateKey(clocking)
 	if earliest == "" {
 		return ""
 	}
@@ -814,13 +1038,24 @@ func monthCoverageNote(clocking map[string][]clockingMsg, now time.Time) string
 
 	if earliestTime.After(firstOfMonth) && now.Day() > 1 {
 		return fmt.Sprintf(
-			"Histórico parcial: dados a partir de %s (limite da API).",
+			"Histórico parcial: dados a partir de %s (primeira marcação conhecida).",
 			earliestTime.Format("02/01"),
 		)
 	}
 	return ""
 }
 
+// earliestDateKey devolve a data mais antiga com marcações ("" sem dados).
+func earliestDateKey(clocking map[string][]clockingMsg) string {
+	earliest := ""
+	for date := range clocking {
+		if earliest == "" || date < earliest {
+			earliest = date
+		}
+	}
+	return earliest
+}
+
 // neutralBalanceColor: verde se positivo, vermelho se negativo, neutro se zero.
 func neutralBalanceColor(balance time.Duration) string {
 	switch {
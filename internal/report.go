@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+// reportSchemaVersion identifica o formato dos relatórios JSON/YAML. Deve ser
+// incrementado a cada mudança incompatível (remoção ou renomeação de campos);
+// campos novos e opcionais não exigem nova versão.
+const reportSchemaVersion = 1
+
+type punchReport struct {
+	ID       string `json:"id"`
+	Date     string `json:"date"`
+	Time     string `json:"time"`
+	Platform string `json:"platform"`
+	At       string `json:"at"`
+}
+
+type statusReport struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	GeneratedAt   string        `json:"generatedAt"`
+	Employee      string        `json:"employee"`
+	Company       string        `json:"company"`
+	TimeTable     string        `json:"timeTable"`
+	Date          string        `json:"date"`
+	Working       bool          `json:"working"`
+	WorkedSeconds int64         `json:"workedSeconds"`
+	Worked        string        `json:"worked"`
+	PredictedExit string        `json:"predictedExit,omitempty"`
+	Punches       []punchReport `json:"punches"`
+}
+
+// adjustmentReport descreve um ajuste detectado em uma marcação passada.
+type adjustmentReport struct {
+	Kind        string `json:"kind"`
+	PunchID     string `json:"punchId"`
+	OldDate     string `json:"oldDate,omitempty"`
+	OldTime     string `json:"oldTime,omitempty"`
+	NewTime     string `json:"newTime,omitempty"`
+	DetectedAt  string `json:"detectedAt"`
+	Description string `json:"description"`
+}
+
+type dayReport struct {
+	Date             string        `json:"date"`
+	WorkedSeconds    int64         `json:"workedSeconds"`
+	Worked           string        `json:"worked"`
+	BalanceSeconds   int64         `json:"balanceSeconds"`
+	Balance          string        `json:"balance"`
+	Complete         bool          `json:"complete"`
+	CountsForBalance bool          `json:"countsForBalance"`
+	Punches          []punchReport `json:"punches"`
+	// Adjustments lista os ajustes feitos pela Senior/RH nas marcações do dia.
+	Adjustments []adjustmentReport `json:"adjustments,omitempty"`
+}
+
+type historyReport struct {
+	SchemaVersion       int         `json:"schemaVersion"`
+	GeneratedAt         string      `json:"generatedAt"`
+	Period              string      `json:"period"`
+	TimeTable           string      `json:"timeTable"`
+	TotalWorkedSeconds  int64       `json:"totalWorkedSeconds"`
+	TotalWorked         string      `json:"totalWorked"`
+	TotalBalanceSeconds int64       `json:"totalBalanceSeconds"`
+	TotalBalance        string      `json:"totalBalance"`
+	Note                string      `json:"note,omitempty"`
+	Days                []dayReport `json:"days"`
+}
+
+func newPunchReports(clockings []clockingMsg) []punchReport {
+	punches := make([]punchReport, 0, len(clockings))
+	for _, c := range clockings {
+		punches = append(punches, punchReport{
+			ID:       c.id,
+			Date:     c.date,
+			Time:     c.time,
+			Platform: c.platform,
+			At:       c.eventTime.Format(time.RFC3339),
+		})
+	}
+	return punches
+}
+
+// buildStatusReport resume o dia de hoje a partir do eventMsg, com o mesmo
+// cálculo de tempo trabalhado usado pelo timer do dashboard.
+func buildStatusReport(msg eventMsg, now time.Time) statusReport {
+	var m clockTimer
+	applyEventMsg(&m, msg)
+
+	report := statusReport{
+		SchemaVersion: reportSchemaVersion,
+		GeneratedAt:   now.Format(time.RFC3339),
+		Employee:      msg.employeeName,
+		Company:       msg.companyName,
+		TimeTable:     msg.timeTable,
+		Date:          m.today,
+		Working:       m.timerRunning,
+		WorkedSeconds: int64(m.elapsed / time.Second),
+		Worked:        core.FormatDuration(m.elapsed),
+		Punches:       newPunchReports(msg.clocking[m.today]),
+	}
+	if predicted, ok := predictTodayExit(msg, now); ok {
+		report.PredictedExit = predicted.Format(time.RFC3339)
+	}
+
+	return report
+}
+
+// buildHistoryReport monta o histórico do período ("week" ou "month") com os
+// mesmos critérios de seleção e saldo da aba Histórico. audits são os ajustes
+// do livro local (ver loadAudits); nil os omite.
+func buildHistoryReport(msg eventMsg, audits []core.AuditEntry, period string, now time.Time) historyReport {
+	var selected []string
+	if period == "month" {
+		selected = selectMonthDates(msg.clocking, now)
+	} else {
+		selected = selectWeekDates(msg.clocking, 0)
+		// A visão semanal lista do mais recente ao mais antigo; fora do gráfico
+		// a leitura cronológica é mais natural.
+		for i, j := 0, len(selected)-1; i < j; i, j = i+1, j-1 {
+			selected[i], selected[j] = selected[j], selected[i]
+		}
+	}
+
+	report := newHistoryReport(msg, audits, period, selected, now)
+	if period == "month" {
+		report.Note = monthCoverageNote(msg.clocking, now)
+	}
+	return report
+}
+
+// buildRangeReport monta o histórico dos dias com marcações entre from e to
+// (chaves "2006-01-02", inclusivas; vazias não limitam).
+func buildRangeReport(msg eventMsg, audits []core.AuditEntry, from, to string, now time.Time) historyReport {
+	var selected []string
+	for date := range msg.clocking {
+		if hideTodayWithoutLunch(date, msg.clocking[date]) {
+			continue
+		}
+		if (from != "" && date < from) || (to != "" && date > to) {
+			continue
+		}
+		selected = append(selected, date)
+	}
+	sort.Strings(selected)
+
+	return newHistoryReport(msg, audits, "range", selected, now)
+}
+
+func newHistoryReport(msg eventMsg, audits []core.AuditEntry, period string, selected []string, now time.Time) historyReport {
+	report := historyReport{
+		SchemaVersion: reportSchemaVersion,
+		GeneratedAt:   now.Format(time.RFC3339),
+		Period:        period,
+		TimeTable:     msg.timeTable,
+		Days:          make([]dayReport, 0, len(selected)),
+	}
+
+	var totalWorked, totalBalance time.Duration
+	for _, date := range selected {
+		db := computeDayBalance(date, msg.clocking[date], msg.timeTable)
+		totalWorked += db.worked
+		if db.countsForBalance() {
+			totalBalance += db.balance
+		}
+
+		report.Days = append(report.Days, dayReport{
+			Date:             date,
+			WorkedSeconds:    int64(db.worked / time.Second),
+			Worked:           core.FormatDuration(db.worked),
+			BalanceSeconds:   int64(db.balance / time.Second),
+			Balance:          core.FormatSignedDuration(db.balance),
+			Complete:         db.complete,
+			CountsForBalance: db.countsForBalance(),
+			Punches:          newPunchReports(msg.clocking[date]),
+			Adjustments:      newAdjustmentReports(auditsForDate(audits, date)),
+		})
+	}
+
+	report.TotalWorkedSeconds = int64(totalWorked / time.Second)
+	report.TotalWorked = core.FormatDuration(totalWorked)
+	report.TotalBalanceSeconds = int64(totalBalance / time.Second)
+	report.TotalBalance = core.FormatSignedDuration(totalBalance)
+
+	return report
+}
+
+func newAdjustmentReports(audits []core.AuditEntry) []adjustmentReport {
+	var reports []adjustmentReport
+	for _, a := range audits {
+		reports = append(reports, adjustmentReport{
+			Kind:        a.Kind,
+			PunchID:     a.ID,
+			OldDate:     a.OldDate,
+			OldTime:     a.OldTime,
+			NewTime:     a.NewTime,
+			DetectedAt:  a.DetectedAt.Format(time.RFC3339),
+			Description: describeAudit(a),
+		})
+	}
+	return reports
+}
+
+func (r statusReport) writeTable(w io.Writer) {
+	situation := "fora"
+	if r.Working {
+		situation = "trabalhando"
+	}
+
+	fmt.Fprintf(w, "Colaborador:    %s\n", r.Employee)
+	fmt.Fprintf(w, "Data atual:     %s\n", formatDateKey(r.Date))
+	fmt.Fprintf(w, "Expediente:     %s\n", r.TimeTable)
+	fmt.Fprintf(w, "Situação:       %s\n", situation)
+	fmt.Fprintf(w, "Trabalhado:     %s\n", r.Worked)
+	if predicted, err := time.Parse(time.RFC3339, r.PredictedExit); err == nil {
+		fmt.Fprintf(w, "Saída prevista: %s\n", predicted.Format("15:04"))
+	}
+	fmt.Fprintf(w, "Registros:      %d\n", len(r.Punches))
+	for _, p := range r.Punches {
+		fmt.Fprintf(w, "  %s %s\n", strings.Split(p.Time, ".")[0], p.Platform)
+	}
+}
+
+func (r historyReport) writeTable(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Data\tTrabalhado\tSaldo\tMarcações")
+
+	for _, day := range r.Days {
+		saldo := "—"
+		if !day.Complete {
+			saldo = "faltam marcações"
+		} else if day.CountsForBalance {
+			saldo = day.Balance
+		}
+
+		marks := make([]string, 0, len(day.Punches))
+		for _, p := range day.Punches {
+			if at, err := time.Parse(time.RFC3339, p.At); err == nil {
+				marks = append(marks, at.Format("15:04"))
+			}
+		}
+
+		if len(day.Adjustments) > 0 {
+			marks = append(marks, "✎")
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			formatDateKey(day.Date),
+			day.Worked,
+			saldo,
+			strings.Join(marks, " "),
+		)
+	}
+	tw.Flush()
+
+	// Uma marcação movida de dia aparece nas duas datas; lista uma vez só.
+	var adjustments []adjustmentReport
+	seen := make(map[adjustmentReport]bool)
+	for _, day := range r.Days {
+		for _, a := range day.Adjustments {
+			if !seen[a] {
+				seen[a] = true
+				adjustments = append(adjustments, a)
+			}
+		}
+	}
+	if len(adjustments) > 0 {
+		fmt.Fprintln(w, "\nAjustes detectados na Senior:")
+		for _, a := range adjustments {
+			fmt.Fprintf(w, "  ✎ %s\n", a.Description)
+		}
+	}
+
+	fmt.Fprintf(w, "\nTotal trabalhado: %s    Saldo do período: %s\n", r.TotalWorked, r.TotalBalance)
+	if r.Note != "" {
+		fmt.Fprintln(w, r.Note)
+	}
+}
+
+// formatDateKey converte uma chave "2006-01-02" para "02/01/2006".
+func formatDateKey(key string) string {
+	if t, ok := parseDateKey(key); ok {
+		return t.Format("02/01/2006")
+	}
+	return key
+}
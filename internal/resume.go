@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/godbus/dbus/v5"
+)
+
+// resumeGap é o atraso de um tick de 1s a partir do qual a máquina é dada
+// como retomada de uma suspensão.
+const resumeGap = 30 * time.Second
+
+// resumeMsg avisa a TUI de que o sistema voltou da suspensão.
+type resumeMsg struct{}
+
+// watchResume assina o sinal PrepareForSleep do logind e avisa no canal
+// devolvido a cada retomada. Sem o barramento do sistema (fora do Linux, em
+// containers), devolve nil e a retomada fica a cargo da detecção pelos ticks.
+func watchResume(ctx context.Context) <-chan struct{} {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil
+	}
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.login1.Manager"),
+		dbus.WithMatchMember("PrepareForSleep"),
+	); err != nil {
+		conn.Close()
+		return nil
+	}
+
+	signals := make(chan *dbus.Signal, 4)
+	conn.Signal(signals)
+	resumed := make(chan struct{}, 1)
+
+	go func() {
+		defer conn.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case signal := <-signals:
+				if signal == nil || signal.Name != "org.freedesktop.login1.Manager.PrepareForSleep" || len(signal.Body) == 0 {
+					continue
+				}
+				// O sinal vem com true antes de suspender e false na volta.
+				if sleeping, ok := signal.Body[0].(bool); ok && !sleeping {
+					select {
+					case resumed <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	return resumed
+}
+
+// waitResume aguarda a próxima retomada avisada por watchResume.
+func waitResume(resumed <-chan struct{}) tea.Cmd {
+	if resumed == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		<-resumed
+		return resumeMsg{}
+	}
+}
+
+// tickResumed informa se o tick recebido agora chegou bem depois do esperado,
+// o que só acontece quando o sistema esteve suspenso. As leituras monotônicas
+// são descartadas porque o relógio monotônico para durante a suspensão.
+func tickResumed(m *clockTimer) bool {
+	if m.tickScheduledAt.IsZero() {
+		return false
+	}
+	return time.Now().Round(0).Sub(m.tickScheduledAt) > time.Second+resumeGap
+}
+
+// refreshNow atualiza as marcações do dashboard sem esperar o próximo ciclo de
+// refresh (retomada da suspensão, virada do dia): outra marcação pode ter sido
+// feita no intervalo. Uma nova tentativa pendente é antecipada.
+func refreshNow(m *clockTimer) tea.Cmd {
+	if m.step != 5 || m.refreshing {
+		return nil
+	}
+	if retryPending(m) {
+		return retryNow(m)
+	}
+	m.refreshing = true
+	return fetchEventsCmd(m)
+}
+
+// dispatchResume trata os avisos de retomada do logind em qualquer etapa. ok é
+// falso para as demais mensagens.
+func dispatchResume(msg tea.Msg, m *clockTimer) (cmd tea.Cmd, ok bool) {
+	if _, ok := msg.(resumeMsg); !ok {
+		return nil, false
+	}
+	updateElapsed(m)
+	return tea.Batch(refreshNow(m), waitResume(m.resumed)), true
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+// retryTickMsg dispara a nova tentativa agendada por scheduleRetry. gen
+// descarta tentativas já superadas (sucesso, retry manual ou novo agendamento).
+type retryTickMsg struct{ gen int }
+
+// connectivityMsg é o resultado de uma checagem de conectividade feita
+// enquanto há uma nova tentativa pendente por falha de rede.
+type connectivityMsg struct {
+	gen    int
+	online bool
+}
+
+// scheduleRetry agenda uma nova tentativa da leitura que falhou com err, com
+// backoff crescente. Em falhas de rede, também checa a conectividade a cada
+// core.ProbeInterval para tentar de novo assim que a rede voltar.
+func scheduleRetry(m *clockTimer, err error) tea.Cmd {
+	m.retryAttempt++
+	m.retryGen++
+	m.retryErr = err
+
+	delay := core.Backoff(m.retryAttempt)
+	m.retryAt = time.Now().Add(delay)
+
+	gen := m.retryGen
+	cmds := []tea.Cmd{
+		tea.Tick(delay, func(time.Time) tea.Msg { return retryTickMsg{gen: gen} }),
+	}
+	if errors.Is(err, senior.ErrNetwork) || errors.Is(err, senior.ErrProxy) {
+		cmds = append(cmds, probeConnectivity(m.ctx, gen))
+	}
+	return tea.Batch(cmds...)
+}
+
+func probeConnectivity(ctx context.Context, gen int) tea.Cmd {
+	return tea.Tick(core.ProbeInterval, func(time.Time) tea.Msg {
+		return connectivityMsg{gen: gen, online: core.Online(ctx)}
+	})
+}
+
+// resetRetry encerra o ciclo de novas tentativas após um sucesso ou uma
+// falha que não deve ser repetida.
+func resetRetry(m *clockTimer) {
+	m.retryAttempt = 0
+	m.retryGen++
+	m.retryErr = nil
+	m.retryAt = time.Time{}
+}
+
+// retryPending informa se há uma nova tentativa agendada.
+func retryPending(m *clockTimer) bool {
+	return !m.retryAt.IsZero()
+}
+
+// dispatchRetry trata os disparos de nova tentativa e de conectividade em
+// qualquer etapa. ok é falso para as demais mensagens.
+func dispatchRetry(msg tea.Msg, m *clockTimer) (cmd tea.Cmd, ok bool) {
+	switch msg := msg.(type) {
+	case retryTickMsg:
+		if msg.gen != m.retryGen || !retryPending(m) {
+			return nil, true
+		}
+		return retryNow(m), true
+
+	case connectivityMsg:
+		if msg.gen != m.retryGen || !retryPending(m) {
+			return nil, true
+		}
+		if !msg.online {
+			return probeConnectivity(m.ctx, msg.gen), true
+		}
+		return retryNow(m), true
+	}
+
+	return nil, false
+}
+
+// retryNow refaz a leitura pendente da etapa atual. Apenas leituras passam
+// por aqui: a marcação de ponto nunca é repetida automaticamente.
+func retryNow(m *clockTimer) tea.Cmd {
+	m.retryGen++
+	m.retryAt = time.Time{}
+
+	switch m.step {
+	case 3:
+		return tea.Batch(authenticateCmd(m), m.spinner.Tick)
+	case 4:
+		return tea.Batch(fetchEventsCmd(m), m.spinner.Tick)
+	case 5:
+		m.refreshing = true
+		return fetchEventsCmd(m)
+	}
+	return nil
+}
+
+// retryLabel descreve a nova tentativa pendente, ex.: "offline, tentando
+// novamente em 12s".
+func retryLabel(m *clockTimer) string {
+	remaining := time.Until(m.retryAt).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	seconds := int(remaining / time.Second)
+
+	switch {
+	case errors.Is(m.retryErr, senior.ErrNetwork):
+		return fmt.Sprintf("offline, tentando novamente em %ds", seconds)
+	case errors.Is(m.retryErr, senior.ErrProxy):
+		return fmt.Sprintf("proxy indisponível, tentando novamente em %ds", seconds)
+	case errors.Is(m.retryErr, senior.ErrRateLimited):
+		return fmt.Sprintf("limite da Senior, tentando novamente em %ds", seconds)
+	default:
+		return fmt.Sprintf("Senior indisponível, tentando novamente em %ds", seconds)
+	}
+}
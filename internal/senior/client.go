@@ -0,0 +1,416 @@
+// Package senior implementa o cliente HTTP das APIs da Senior X usadas pelo
+// Clockwerk: login, consulta e registro de marcações.
+package senior
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultGatewayURL  = "https://snr-getaway.fly.dev"
+	DefaultPlatformURL = "https://platform.senior.com.br"
+	// DefaultTenant é usado enquanto o domínio do usuário não é conhecido.
+	DefaultTenant  = "senior.com.br"
+	DefaultTimeout = 10 * time.Second
+
+	// EventsPageSize é maior que o padrão da Senior para que a primeira
+	// página cubra o mês na visão mensal do Histórico.
+	EventsPageSize = 200
+	// maxEventPages limita a paginação de QueryClockingEvents.
+	maxEventPages = 50
+)
+
+// Config define os endereços e a identificação usados pelo Client. Campos
+// vazios assumem os valores padrão.
+type Config struct {
+	// GatewayURL é a base do gateway de login (POST /senior/login), usado
+	// apenas com UseGateway ou GatewayFallback.
+	GatewayURL string
+	// UseGateway envia todo login ao gateway, por exemplo a um
+	// `clockwerk gateway serve` mantido pela empresa.
+	UseGateway bool
+	// GatewayFallback recorre ao gateway quando o login na plataforma falha
+	// por rede ou instabilidade. Desligado, as credenciais só vão para
+	// PlatformURL.
+	GatewayFallback bool
+	// PlatformURL é a base da plataforma Senior X.
+	PlatformURL string
+	// Tenant fixa o segmento /t/<tenant>/ das rotas da plataforma. Vazio, o
+	// tenant vem do domínio do usuário (ver WithTenant e TenantFromDomain).
+	Tenant string
+	// UserAgent e AppVersion identificam o cliente nas requisições.
+	UserAgent  string
+	AppVersion string
+}
+
+// Client chama as APIs da Senior. É seguro para uso concorrente e deve ser
+// compartilhado, para reaproveitar as conexões do http.Client.
+type Client struct {
+	config     Config
+	httpClient *http.Client
+	// tenantPinned indica um Tenant fixado na configuração, que WithTenant
+	// não substitui.
+	tenantPinned bool
+
+	// Observe, quando definido, é chamado ao fim de cada requisição com a
+	// operação ("login", "events" ou "punch"), a resposta ou o erro e a
+	// duração.
+	Observe func(op string, resp *http.Response, err error, elapsed time.Duration)
+}
+
+// NewClient cria um Client. Sem httpClient, usa um com timeout de
+// DefaultTimeout.
+func NewClient(config Config, httpClient *http.Client) *Client {
+	if config.GatewayURL == "" {
+		config.GatewayURL = DefaultGatewayURL
+	}
+	if config.PlatformURL == "" {
+		config.PlatformURL = DefaultPlatformURL
+	}
+	tenantPinned := config.Tenant != ""
+	if !tenantPinned {
+		config.Tenant = DefaultTenant
+	}
+	config.GatewayURL = strings.TrimRight(config.GatewayURL, "/")
+	config.PlatformURL = strings.TrimRight(config.PlatformURL, "/")
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	return &Client{config: config, httpClient: httpClient, tenantPinned: tenantPinned}
+}
+
+// WithTenant devolve uma cópia do cliente que usa tenant nas rotas da
+// plataforma, compartilhando as conexões. Um Tenant fixado na configuração
+// prevalece.
+func (c *Client) WithTenant(tenant string) *Client {
+	if c.tenantPinned || tenant == "" || tenant == c.config.Tenant {
+		return c
+	}
+	tenantClient := *c
+	tenantClient.config.Tenant = tenant
+	return &tenantClient
+}
+
+// TenantFromDomain devolve o tenant da Senior X de um domínio de empresa, o
+// mesmo usado após o @ no login ("Empresa.com.br " → "empresa.com.br").
+func TenantFromDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "@")
+	return strings.TrimSuffix(domain, ".")
+}
+
+// tenantPattern aceita domínios como "empresa.com.br", já normalizados por
+// TenantFromDomain.
+var tenantPattern = regexp.MustCompile(`^([a-z0-9]+(-[a-z0-9]+)*\.)+[a-z]{2,}$`)
+
+// ValidateTenant confere que tenant (ver TenantFromDomain) é um domínio
+// válido antes de ele compor as rotas da plataforma.
+func ValidateTenant(tenant string) error {
+	if tenant == "" {
+		return errors.New("o domínio não pode estar vazio")
+	}
+	if !tenantPattern.MatchString(tenant) {
+		return fmt.Errorf("o domínio %q não é válido", tenant)
+	}
+	return nil
+}
+
+// tenantFromUser extrai o tenant de um usuário no formato "cpf@dominio".
+func tenantFromUser(user string) string {
+	_, domain, ok := strings.Cut(user, "@")
+	if !ok {
+		return ""
+	}
+	return TenantFromDomain(domain)
+}
+
+// Config devolve a configuração efetiva do cliente.
+func (c *Client) Config() Config {
+	return c.config
+}
+
+func (c *Client) platformURL(path string) string {
+	return fmt.Sprintf("%s/t/%s/bridge/1.0/rest/%s", c.config.PlatformURL, url.PathEscape(c.config.Tenant), path)
+}
+
+// anonymousURL monta a URL das primitivas que dispensam token, como o login,
+// servidas pela plataforma sob /anonymous/rest.
+func (c *Client) anonymousURL(path string) string {
+	return fmt.Sprintf("%s/t/%s/bridge/1.0/anonymous/rest/%s", c.config.PlatformURL, url.PathEscape(c.config.Tenant), path)
+}
+
+// post serializa body, envia a requisição e devolve a resposta; o chamador
+// fecha o corpo.
+func (c *Client) post(ctx context.Context, op, url, token string, body any) (*http.Response, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	if c.config.AppVersion != "" {
+		req.Header.Set("X-App-Version", c.config.AppVersion)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if c.Observe != nil {
+		c.Observe(op, resp, err, time.Since(start))
+	}
+	if err != nil {
+		return nil, transportError(err)
+	}
+
+	return resp, nil
+}
+
+// decodeResponse decodifica uma resposta de sucesso em v; para os demais
+// status, devolve o erro tipado correspondente. unauthorized é o erro usado
+// para respostas 401.
+func decodeResponse(resp *http.Response, v any, unauthorized error) error {
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("%w: %w", ErrDecode, err)
+		}
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var errorResponse errorResponse
+	json.Unmarshal(body, &errorResponse)
+
+	// No login, a plataforma responde 401 ou 403 e o gateway 422 para CPF
+	// ou senha inválidos.
+	status := resp.StatusCode
+	if unauthorized == ErrUnauthorized &&
+		(status == http.StatusForbidden || status == http.StatusUnprocessableEntity) {
+		status = http.StatusUnauthorized
+	}
+	if errorResponse.Message != "" {
+		body = []byte(errorResponse.Message)
+	}
+	return statusError(status, errorResponse.Message, string(body), unauthorized)
+}
+
+// Token é o resultado de um login. ExpiresAt fica zerado quando a Senior não
+// informa a validade.
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// Login autentica user ("cpf@dominio") direto na plataforma da Senior (ou
+// no gateway, com UseGateway) e devolve o token. Com GatewayFallback, as
+// credenciais só seguem para o gateway quando a plataforma está indisponível
+// (ver platformUnavailable); credenciais recusadas, tenant inexistente ou uma
+// falha de TLS nunca levam a senha para outro host.
+func (c *Client) Login(ctx context.Context, user, password string) (Token, error) {
+	if c.config.UseGateway {
+		return c.gatewayLogin(ctx, user, password)
+	}
+
+	token, err := c.platformLogin(ctx, user, password)
+	if err == nil || !c.config.GatewayFallback || !platformUnavailable(err) {
+		return token, err
+	}
+	return c.gatewayLogin(ctx, user, password)
+}
+
+// platformUnavailable indica uma falha da plataforma em si: rede, limite de
+// requisições ou erro 5xx.
+func platformUnavailable(err error) bool {
+	var serverErr *ErrServer
+	switch {
+	case errors.Is(err, ErrNetwork), errors.Is(err, ErrRateLimited):
+		return true
+	case errors.As(err, &serverErr):
+		return serverErr.Status >= 500
+	}
+	return false
+}
+
+// platformLogin usa a ação de login da própria plataforma, que devolve o
+// token dentro de jsonToken (um JSON serializado como string).
+func (c *Client) platformLogin(ctx context.Context, user, password string) (Token, error) {
+	tenant := tenantFromUser(user)
+	if tenant != "" {
+		// O usuário pode vir de fora (gateway serve): um domínio inválido não
+		// chega à URL.
+		if err := ValidateTenant(tenant); err != nil {
+			return Token{}, detail(ErrTenantNotFound, err.Error())
+		}
+	}
+	c = c.WithTenant(tenant)
+	resp, err := c.post(ctx, "login",
+		c.anonymousURL("platform/authentication/actions/login"),
+		"", platformLoginRequest{
+			Username: user,
+			Password: password,
+		})
+	if err != nil {
+		return Token{}, err
+	}
+	defer resp.Body.Close()
+
+	var response platformLoginResponse
+	if err := decodeResponse(resp, &response, ErrUnauthorized); err != nil {
+		return Token{}, tenantError(err, c.config.Tenant)
+	}
+
+	// Sem jsonToken, a plataforma pede uma etapa extra (troca de senha ou
+	// segundo fator) que só o navegador conclui.
+	if response.JSONToken == "" {
+		return Token{}, detail(ErrUnauthorized, "o login exige uma etapa adicional na Senior X; use o login por token (SSO)")
+	}
+
+	var token platformToken
+	if err := json.Unmarshal([]byte(response.JSONToken), &token); err != nil {
+		return Token{}, fmt.Errorf("%w: jsonToken: %w", ErrDecode, err)
+	}
+	if token.AccessToken == "" {
+		return Token{}, fmt.Errorf("%w: jsonToken sem access_token", ErrDecode)
+	}
+	result := Token{AccessToken: token.AccessToken}
+	if token.ExpiresIn > 0 {
+		result.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return result, nil
+}
+
+// gatewayLogin autentica pelo gateway externo (POST /senior/login).
+func (c *Client) gatewayLogin(ctx context.Context, user, password string) (Token, error) {
+	resp, err := c.post(ctx, "login", c.config.GatewayURL+"/senior/login", "", loginRequest{
+		User:     user,
+		Password: password,
+	})
+	if err != nil {
+		return Token{}, err
+	}
+	defer resp.Body.Close()
+
+	// O gateway responde 404 quando o domínio não é uma empresa na Senior X.
+	var successResponse loginResponse
+	if err := decodeResponse(resp, &successResponse, ErrUnauthorized); err != nil {
+		return Token{}, tenantError(err, tenantFromUser(user))
+	}
+	return Token{AccessToken: successResponse.Token}, nil
+}
+
+// ClockingEvents busca uma página de eventos do usuário autenticado. Um token
+// recusado resulta em ErrTokenExpired.
+func (c *Client) ClockingEvents(ctx context.Context, token string, page int) ([]ClockingEvent, error) {
+	requestBody := clockingEventRequest{
+		Filter: requestFilter{
+			ActivePlatformUser: true,
+			PageInfo: pageInfo{
+				Page:     page,
+				PageSize: strconv.Itoa(EventsPageSize),
+			},
+			NameSearch: "",
+			Sort: sortOrder{
+				Field: nil,
+				Order: "ASC",
+			},
+		},
+	}
+
+	resp, err := c.post(ctx, "events",
+		c.platformURL("hcm/pontomobile/queries/clockingEventByActiveUserQuery"),
+		token, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response clockingEventResponse
+	if err := decodeResponse(resp, &response, ErrTokenExpired); err != nil {
+		return nil, tenantError(err, c.config.Tenant)
+	}
+	return response.Result, nil
+}
+
+// QueryClockingEvents percorre as páginas da query de eventos até cobrir o
+// período [from, to] (chaves "2006-01-02"; vazias deixam o lado em aberto) e
+// devolve apenas os eventos dentro dele. A query não filtra por data, então a
+// paginação para quando a página já passou do período, na direção em que a
+// Senior ordena os resultados, ou quando vem incompleta.
+func (c *Client) QueryClockingEvents(ctx context.Context, token, from, to string) ([]ClockingEvent, error) {
+	var events []ClockingEvent
+	seen := make(map[string]bool)
+
+	for page := 0; page < maxEventPages; page++ {
+		batch, err := c.ClockingEvents(ctx, token, page)
+		if err != nil {
+			return nil, err
+		}
+
+		oldest, newest := "", ""
+		for _, event := range batch {
+			if oldest == "" || event.DateEvent < oldest {
+				oldest = event.DateEvent
+			}
+			if event.DateEvent > newest {
+				newest = event.DateEvent
+			}
+			if seen[event.ID] || (from != "" && event.DateEvent < from) || (to != "" && event.DateEvent > to) {
+				continue
+			}
+			seen[event.ID] = true
+			events = append(events, event)
+		}
+
+		if len(batch) < EventsPageSize {
+			break
+		}
+		descending := batch[0].DateEvent >= batch[len(batch)-1].DateEvent
+		if descending && from != "" && oldest < from {
+			break
+		}
+		if !descending && to != "" && newest > to {
+			break
+		}
+	}
+
+	return events, nil
+}
+
+// PostClockingEvent registra uma marcação para o usuário autenticado.
+func (c *Client) PostClockingEvent(ctx context.Context, token string, body ClockingRequest) (PostClockingEventResponse, error) {
+	resp, err := c.post(ctx, "punch",
+		c.platformURL("hcm/pontomobile_clocking_event/actions/clockingEventImportByBrowser"),
+		token, body)
+	if err != nil {
+		return PostClockingEventResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var result PostClockingEventResponse
+	if err := decodeResponse(resp, &result, ErrTokenExpired); err != nil {
+		return PostClockingEventResponse{}, tenantError(err, c.config.Tenant)
+	}
+	return result, nil
+}
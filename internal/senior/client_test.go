@@ -0,0 +1,169 @@
+package senior
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// eventsOn gera n eventos na data, com IDs prefix-0, prefix-1...
+func eventsOn(prefix, date string, n int) []ClockingEvent {
+	events := make([]ClockingEvent, n)
+	for i := range events {
+		events[i] = ClockingEvent{ID: fmt.Sprintf("%s-%d", prefix, i), DateEvent: date, TimeEvent: "08:00:00"}
+	}
+	return events
+}
+
+func concatEvents(parts ...[]ClockingEvent) []ClockingEvent {
+	var events []ClockingEvent
+	for _, part := range parts {
+		events = append(events, part...)
+	}
+	return events
+}
+
+// pagesServer responde a query de eventos com pages[page] e conta as páginas
+// pedidas.
+func pagesServer(t *testing.T, pages [][]ClockingEvent, requested *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body clockingEventRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("corpo inválido: %v", err)
+		}
+		page := body.Filter.PageInfo.Page
+		*requested = append(*requested, page)
+
+		var result []ClockingEvent
+		if page < len(pages) {
+			result = pages[page]
+		}
+		json.NewEncoder(w).Encode(clockingEventResponse{Result: result})
+	}))
+}
+
+func TestQueryClockingEvents(t *testing.T) {
+	full := func(prefix, date string) []ClockingEvent { return eventsOn(prefix, date, EventsPageSize) }
+
+	tests := []struct {
+		name      string
+		pages     [][]ClockingEvent
+		from, to  string
+		requested []int
+		count     int
+		dates     []string
+	}{
+		{
+			name:      "página incompleta encerra a busca",
+			pages:     [][]ClockingEvent{eventsOn("a", "2024-03-15", 3)},
+			requested: []int{0},
+			count:     3,
+			dates:     []string{"2024-03-15"},
+		},
+		{
+			name: "decrescente para na página que passa de from",
+			pages: [][]ClockingEvent{
+				full("a", "2024-03-15"),
+				concatEvents(eventsOn("b", "2024-03-14", 100), eventsOn("c", "2024-03-10", 100)),
+				full("d", "2024-03-01"),
+			},
+			from:      "2024-03-12",
+			requested: []int{0, 1},
+			count:     EventsPageSize + 100,
+			dates:     []string{"2024-03-14", "2024-03-15"},
+		},
+		{
+			name: "dia dividido entre páginas é buscado por inteiro",
+			pages: [][]ClockingEvent{
+				concatEvents(eventsOn("a", "2024-03-15", 150), eventsOn("b", "2024-03-14", 50)),
+				concatEvents(eventsOn("c", "2024-03-14", 30), eventsOn("d", "2024-03-13", 170)),
+				full("e", "2024-03-12"),
+			},
+			from:      "2024-03-14",
+			to:        "2024-03-14",
+			requested: []int{0, 1},
+			count:     80,
+			dates:     []string{"2024-03-14"},
+		},
+		{
+			name: "crescente para na página que passa de to",
+			pages: [][]ClockingEvent{
+				concatEvents(eventsOn("a", "2024-03-01", 100), eventsOn("b", "2024-03-02", 100)),
+				concatEvents(eventsOn("c", "2024-03-03", 100), eventsOn("d", "2024-03-05", 100)),
+				full("e", "2024-03-06"),
+			},
+			to:        "2024-03-04",
+			requested: []int{0, 1},
+			count:     300,
+			dates:     []string{"2024-03-01", "2024-03-02", "2024-03-03"},
+		},
+		{
+			name: "eventos repetidos entre páginas contam uma vez",
+			pages: [][]ClockingEvent{
+				concatEvents(eventsOn("a", "2024-03-15", 190), eventsOn("b", "2024-03-14", 10)),
+				concatEvents(eventsOn("b", "2024-03-14", 10), eventsOn("c", "2024-03-13", 5)),
+			},
+			requested: []int{0, 1},
+			count:     205,
+			dates:     []string{"2024-03-13", "2024-03-14", "2024-03-15"},
+		},
+		{
+			name: "período aberto respeita o limite de páginas",
+			pages: func() [][]ClockingEvent {
+				pages := make([][]ClockingEvent, maxEventPages+5)
+				for i := range pages {
+					pages[i] = full(fmt.Sprintf("p%d", i), fmt.Sprintf("2024-%02d-01", 12-i%12))
+				}
+				return pages
+			}(),
+			requested: func() []int {
+				pages := make([]int, maxEventPages)
+				for i := range pages {
+					pages[i] = i
+				}
+				return pages
+			}(),
+			count: maxEventPages * EventsPageSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested []int
+			server := pagesServer(t, tt.pages, &requested)
+			defer server.Close()
+
+			client := NewClient(Config{PlatformURL: server.URL, Tenant: "empresa.com.br"}, server.Client())
+			events, err := client.QueryClockingEvents(context.Background(), "token", tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(requested, tt.requested) {
+				t.Errorf("páginas pedidas = %v, esperado %v", requested, tt.requested)
+			}
+			if len(events) != tt.count {
+				t.Errorf("%d eventos, esperado %d", len(events), tt.count)
+			}
+			if tt.dates != nil {
+				set := make(map[string]bool)
+				for _, event := range events {
+					set[event.DateEvent] = true
+				}
+				var dates []string
+				for date := range set {
+					dates = append(dates, date)
+				}
+				sort.Strings(dates)
+				if !reflect.DeepEqual(dates, tt.dates) {
+					t.Errorf("datas = %v, esperado %v", dates, tt.dates)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package senior
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Erros devolvidos pelo Client. Use errors.Is/errors.As: as mensagens trazem
+// também o detalhe retornado pela Senior ou a causa original.
+var (
+	// ErrUnauthorized indica credenciais recusadas no login (ou um login que
+	// a Senior não conclui sem o navegador).
+	ErrUnauthorized = errors.New("credenciais recusadas pela Senior")
+	// ErrTokenExpired indica um token expirado ou inválido em uma chamada
+	// autenticada; refazer o login resolve.
+	ErrTokenExpired = errors.New("token expirado ou inválido")
+	// ErrRateLimited indica que a Senior limitou as requisições (HTTP 429).
+	ErrRateLimited = errors.New("limite de requisições da Senior atingido")
+	// ErrNetwork indica que a requisição não chegou a ter resposta (DNS,
+	// conexão, TLS, timeout).
+	ErrNetwork = errors.New("falha de rede ao acessar a Senior")
+	// ErrTenantNotFound indica que a plataforma não reconhece o tenant
+	// derivado do domínio (rota /t/<tenant>/ inexistente).
+	ErrTenantNotFound = errors.New("domínio não corresponde a um tenant da Senior X")
+	// ErrDecode indica uma resposta em formato inesperado.
+	ErrDecode = errors.New("resposta inválida da Senior")
+)
+
+// ErrServer é uma resposta com status inesperado (em geral 5xx).
+type ErrServer struct {
+	Status int
+	Body   string
+}
+
+func (e *ErrServer) Error() string {
+	body := strings.TrimSpace(e.Body)
+	if body == "" {
+		return fmt.Sprintf("erro inesperado da Senior (status %d)", e.Status)
+	}
+	return fmt.Sprintf("erro inesperado da Senior (status %d): %s", e.Status, body)
+}
+
+// statusError traduz o status de uma resposta sem sucesso. unauthorized é o
+// erro usado para 401 (ErrUnauthorized no login, ErrTokenExpired nas demais).
+func statusError(status int, message string, body string, unauthorized error) error {
+	switch {
+	case status == http.StatusUnauthorized:
+		return detail(unauthorized, message)
+	case status == http.StatusTooManyRequests:
+		return detail(ErrRateLimited, message)
+	case status == http.StatusProxyAuthRequired:
+		return detail(ErrProxyAuth, message)
+	default:
+		return &ErrServer{Status: status, Body: body}
+	}
+}
+
+// tenantError traduz um 404 em uma rota da plataforma em ErrTenantNotFound.
+func tenantError(err error, tenant string) error {
+	var serverErr *ErrServer
+	if errors.As(err, &serverErr) && serverErr.Status == http.StatusNotFound {
+		return detail(ErrTenantNotFound, tenant)
+	}
+	return err
+}
+
+func detail(kind error, message string) error {
+	if message == "" {
+		return kind
+	}
+	return fmt.Errorf("%w: %s", kind, message)
+}
@@ -0,0 +1,245 @@
+package senior
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GatewayOptions configura o servidor do gateway de login.
+type GatewayOptions struct {
+	// RatePerMinute e Burst limitam as tentativas de login por IP e por
+	// usuário. Zero desliga o limite.
+	RatePerMinute float64
+	Burst         int
+	// TrustProxy usa a última entrada de X-Forwarded-For, a acrescentada
+	// pelo proxy reverso à frente do gateway, como IP do cliente.
+	TrustProxy bool
+	// Logger recebe uma linha por requisição (IP, status e duração). Nunca
+	// registra o corpo, o usuário ou a senha.
+	Logger *log.Logger
+}
+
+// maxLoginBody limita o corpo de POST /senior/login.
+const maxLoginBody = 4 << 10
+
+// NewGatewayHandler devolve um http.Handler que implementa o contrato do
+// gateway (POST /senior/login: loginRequest → loginResponse ou
+// errorResponse), autenticando direto na plataforma com client.
+func NewGatewayHandler(client *Client, opts GatewayOptions) http.Handler {
+	g := &gateway{
+		client: client,
+		opts:   opts,
+		byIP:   newRateLimiter(opts.RatePerMinute, opts.Burst),
+		byUser: newRateLimiter(opts.RatePerMinute, opts.Burst),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /senior/login", g.handleLogin)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return g.logRequests(mux)
+}
+
+type gateway struct {
+	client *Client
+	opts   GatewayOptions
+	byIP   *rateLimiter
+	byUser *rateLimiter
+}
+
+func (g *gateway) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if wait, ok := g.byIP.allow(g.clientIP(r)); !ok {
+		writeRateLimited(w, wait)
+		return
+	}
+
+	var req loginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLoginBody)).Decode(&req); err != nil {
+		writeGatewayError(w, http.StatusBadRequest, "corpo inválido: esperado {\"user\", \"password\"}")
+		return
+	}
+	if !strings.Contains(req.User, "@") || req.Password == "" {
+		writeGatewayError(w, http.StatusBadRequest, "informe user (cpf@dominio) e password")
+		return
+	}
+
+	if wait, ok := g.byUser.allow(strings.ToLower(req.User)); !ok {
+		writeRateLimited(w, wait)
+		return
+	}
+
+	token, err := g.client.platformLogin(r.Context(), req.User, req.Password)
+	if err != nil {
+		status, message := gatewayStatus(err)
+		writeGatewayError(w, status, message)
+		return
+	}
+
+	writeGatewayJSON(w, http.StatusOK, loginResponse{Token: token.AccessToken})
+}
+
+// gatewayStatus traduz a falha do login na plataforma na resposta do
+// gateway. Os códigos seguem o que Client.gatewayLogin espera.
+func gatewayStatus(err error) (int, string) {
+	var serverErr *ErrServer
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized, strings.TrimPrefix(err.Error(), ErrUnauthorized.Error()+": ")
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests, ErrRateLimited.Error()
+	case errors.Is(err, ErrTenantNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.As(err, &serverErr):
+		return http.StatusBadGateway, fmt.Sprintf("a plataforma respondeu com status %d", serverErr.Status)
+	case errors.Is(err, ErrNetwork):
+		return http.StatusBadGateway, "plataforma inacessível"
+	case errors.Is(err, ErrTLS), errors.Is(err, ErrProxy), errors.Is(err, ErrTransportConfig):
+		return http.StatusBadGateway, "falha de rede do gateway até a plataforma"
+	default:
+		return http.StatusBadGateway, "resposta inesperada da plataforma"
+	}
+}
+
+// clientIP identifica o cliente para o limite por IP. Com TrustProxy, vale a
+// última entrada de X-Forwarded-For, a acrescentada pelo proxy confiável; as
+// anteriores vêm do próprio cliente e podem ser forjadas.
+func (g *gateway) clientIP(r *http.Request) string {
+	if g.opts.TrustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			forwarded := values[len(values)-1]
+			if i := strings.LastIndex(forwarded, ","); i >= 0 {
+				forwarded = forwarded[i+1:]
+			}
+			if ip := strings.TrimSpace(forwarded); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (g *gateway) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if g.opts.Logger != nil {
+			g.opts.Logger.Printf("%s %s %s %d %s",
+				g.clientIP(r), r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+		}
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func writeRateLimited(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	writeGatewayError(w, http.StatusTooManyRequests, "muitas tentativas de login, aguarde")
+}
+
+func writeGatewayError(w http.ResponseWriter, status int, message string) {
+	writeGatewayJSON(w, status, errorResponse{Message: message, Errors: []string{message}})
+}
+
+func writeGatewayJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// rateLimiter é um token bucket por chave (IP ou usuário).
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens por segundo
+	burst   float64
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// maxBuckets limita a quantidade de chaves acompanhadas. Ao atingi-lo, os
+// buckets já cheios (IPs e usuários que não voltaram) são descartados; se
+// ainda assim não houver espaço, chaves novas são recusadas.
+const maxBuckets = 10000
+
+func newRateLimiter(perMinute float64, burst int) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    perMinute / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow consome um token de key. Sem token, devolve quanto falta para o
+// próximo.
+func (l *rateLimiter) allow(key string) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(now)
+		}
+		// Com o mapa cheio de buckets em uso, negar o login é preferível a
+		// descartar o limite de alguém.
+		if len(l.buckets) >= maxBuckets {
+			return time.Duration(float64(time.Second) / l.rate), false
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+func (l *rateLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
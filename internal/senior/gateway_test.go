@@ -0,0 +1,146 @@
+package senior
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGatewayClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "sem proxy", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "X-Forwarded-For ignorado sem TrustProxy", remoteAddr: "203.0.113.7:51234", forwarded: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "entrada do proxy", trustProxy: true, remoteAddr: "10.0.0.2:8080", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "entrada forjada à esquerda", trustProxy: true, remoteAddr: "10.0.0.2:8080", forwarded: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "espaços na lista", trustProxy: true, remoteAddr: "10.0.0.2:8080", forwarded: []string{"1.2.3.4 ,  198.51.100.1 "}, want: "198.51.100.1"},
+		{name: "cabeçalho repetido", trustProxy: true, remoteAddr: "10.0.0.2:8080", forwarded: []string{"1.2.3.4", "198.51.100.1"}, want: "198.51.100.1"},
+		{name: "última entrada vazia", trustProxy: true, remoteAddr: "10.0.0.2:8080", forwarded: []string{"1.2.3.4,"}, want: "10.0.0.2"},
+		{name: "sem cabeçalho atrás do proxy", trustProxy: true, remoteAddr: "10.0.0.2:8080", want: "10.0.0.2"},
+		{name: "RemoteAddr sem porta", remoteAddr: "@", want: "@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gateway{opts: GatewayOptions{TrustProxy: tt.trustProxy}}
+			req := httptest.NewRequest(http.MethodPost, "/senior/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if got := g.clientIP(req); got != tt.want {
+				t.Errorf("clientIP = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := newRateLimiter(60, 3)
+
+	for i := range 3 {
+		if _, ok := l.allow("a"); !ok {
+			t.Fatalf("tentativa %d recusada dentro do burst", i+1)
+		}
+	}
+	wait, ok := l.allow("a")
+	if ok {
+		t.Fatal("tentativa além do burst aceita")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("espera = %v, esperado até 1s", wait)
+	}
+	if _, ok := l.allow("b"); !ok {
+		t.Error("outra chave recusada pelo limite de a")
+	}
+
+	if _, ok := newRateLimiter(0, 3).allow("a"); !ok {
+		t.Error("limite desligado recusou")
+	}
+}
+
+func TestRateLimiterCap(t *testing.T) {
+	tests := []struct {
+		name string
+		// idle deixa os buckets existentes cheios, como chaves que não
+		// voltaram desde o último login.
+		idle bool
+		want bool
+	}{
+		{name: "buckets ociosos são descartados", idle: true, want: true},
+		{name: "buckets em uso recusam chaves novas", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(1, 2)
+			for i := range maxBuckets {
+				if _, ok := l.allow(fmt.Sprintf("ip-%d", i)); !ok {
+					t.Fatalf("chave %d recusada antes do limite", i)
+				}
+			}
+			if tt.idle {
+				for _, b := range l.buckets {
+					b.last = b.last.Add(-time.Hour)
+				}
+			}
+
+			if _, ok := l.allow("novo"); ok != tt.want {
+				t.Errorf("chave nova aceita = %v, esperado %v", ok, tt.want)
+			}
+			if len(l.buckets) > maxBuckets {
+				t.Errorf("%d buckets, limite %d", len(l.buckets), maxBuckets)
+			}
+		})
+	}
+}
+
+func TestGatewayStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "credenciais recusadas", err: detail(ErrUnauthorized, "senha inválida"), want: http.StatusUnauthorized},
+		{name: "limite da plataforma", err: ErrRateLimited, want: http.StatusTooManyRequests},
+		{name: "tenant inexistente", err: detail(ErrTenantNotFound, "empresa.com.br"), want: http.StatusNotFound},
+		{name: "erro 5xx", err: &ErrServer{Status: 503}, want: http.StatusBadGateway},
+		{name: "rede", err: fmt.Errorf("%w: timeout", ErrNetwork), want: http.StatusBadGateway},
+		{name: "proxy", err: fmt.Errorf("%w: proxyconnect", ErrProxy), want: http.StatusBadGateway},
+		{name: "inesperado", err: errors.New("outro"), want: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := gatewayStatus(tt.err); got != tt.want {
+				t.Errorf("status = %d, esperado %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatewayTenantNotFound(t *testing.T) {
+	platform := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer platform.Close()
+
+	upstream := NewClient(Config{PlatformURL: platform.URL}, platform.Client())
+	gw := httptest.NewServer(NewGatewayHandler(upstream, GatewayOptions{}))
+	defer gw.Close()
+
+	client := NewClient(Config{GatewayURL: gw.URL, UseGateway: true}, gw.Client())
+	_, err := client.Login(context.Background(), "12345678901@inexistente.com.br", "senha")
+	if !errors.Is(err, ErrTenantNotFound) {
+		t.Errorf("Login = %v, esperado %v", err, ErrTenantNotFound)
+	}
+}
@@ -0,0 +1,131 @@
+package senior
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// foreignCert gera um certificado autoassinado que o servidor de teste anexa
+// à cadeia, como faria um intermediário com o certificado verdadeiro da
+// Senior.
+func foreignCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "platform.senior.com.br"},
+		DNSNames:     []string{"platform.senior.com.br"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestSPKIPinning(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	leaf := server.Certificate()
+	foreign := foreignCert(t)
+	// O servidor envia também um certificado fora da cadeia verificada.
+	server.TLS.Certificates[0].Certificate = append(server.TLS.Certificates[0].Certificate, foreign.Raw)
+
+	tests := []struct {
+		name  string
+		pins  []string
+		hosts []string
+		want  error
+	}{
+		{name: "chave da cadeia verificada", pins: []string{spkiPin(leaf)}, hosts: []string{"example.com"}},
+		{name: "prefixo sha256/", pins: []string{"sha256/" + spkiPin(leaf)}, hosts: []string{"example.com"}},
+		{name: "um dos pins confere", pins: []string{spkiPin(foreign), spkiPin(leaf)}, hosts: []string{"example.com"}},
+		{name: "host em maiúsculas", pins: []string{spkiPin(leaf)}, hosts: []string{"EXAMPLE.COM"}},
+		{name: "chave só anexada pelo servidor", pins: []string{spkiPin(foreign)}, hosts: []string{"example.com"}, want: ErrPinMismatch},
+		{name: "host sem pinning", pins: []string{spkiPin(foreign)}, hosts: []string{"platform.senior.com.br"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewTransport(TransportConfig{PinnedSPKI: tt.pins, PinnedHosts: tt.hosts})
+			if err != nil {
+				t.Fatal(err)
+			}
+			roots := x509.NewCertPool()
+			roots.AddCert(leaf)
+			transport.TLSClientConfig.RootCAs = roots
+			transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+			}
+			defer transport.CloseIdleConnections()
+
+			resp, err := (&http.Client{Transport: transport}).Get("https://example.com/")
+			if err == nil {
+				resp.Body.Close()
+			}
+			if tt.want == nil && err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("erro = %v, esperado %v", err, tt.want)
+			}
+			if tt.want != nil && !errors.Is(transportError(err), ErrTLS) {
+				t.Errorf("%v não foi classificado como %v", err, ErrTLS)
+			}
+		})
+	}
+}
+
+func TestSPKIPinningInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		pin  string
+	}{
+		{name: "fora de base64", pin: "não é base64"},
+		{name: "tamanho errado", pin: base64.StdEncoding.EncodeToString([]byte("curto"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTransport(TransportConfig{PinnedSPKI: []string{tt.pin}, PinnedHosts: []string{"example.com"}})
+			if !errors.Is(err, ErrTransportConfig) {
+				t.Errorf("NewTransport = %v, esperado %v", err, ErrTransportConfig)
+			}
+		})
+	}
+}
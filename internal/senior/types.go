@@ -0,0 +1,140 @@
+package senior
+
+type loginRequest struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+type loginResponse struct {
+	Token string `json:"token"`
+}
+
+type platformLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type platformLoginResponse struct {
+	JSONToken string `json:"jsonToken"`
+}
+
+// platformToken é o conteúdo de jsonToken no login da plataforma.
+type platformToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type errorResponse struct {
+	Message string   `json:"message"`
+	Errors  []string `json:"errors"`
+}
+
+type requestFilter struct {
+	ActivePlatformUser bool      `json:"activePlatformUser"`
+	PageInfo           pageInfo  `json:"pageInfo"`
+	NameSearch         string    `json:"nameSearch"`
+	Sort               sortOrder `json:"sort"`
+}
+
+type pageInfo struct {
+	Page     int    `json:"page"`
+	PageSize string `json:"pageSize"`
+}
+
+type sortOrder struct {
+	Field interface{} `json:"field"`
+	Order string      `json:"order"`
+}
+
+type clockingEventRequest struct {
+	Filter requestFilter `json:"filter"`
+}
+
+type clockingEventResponse struct {
+	Result []ClockingEvent `json:"result"`
+}
+
+type ClockingEventImported struct {
+	DateEvent string `json:"dateEvent"`
+	TimeEvent string `json:"timeEvent"`
+}
+
+type ClockingResult struct {
+	EventImported ClockingEventImported `json:"clockingEventImported"`
+}
+
+// PostClockingEventResponse é a resposta do registro de uma marcação.
+type PostClockingEventResponse struct {
+	Result ClockingResult `json:"clockingResult"`
+}
+
+type ClockingCompany struct {
+	ID         string `json:"id"`
+	ArpID      string `json:"arpId"`
+	Identifier string `json:"identifier"`
+	Caepf      string `json:"caepf"`
+	CnoNumber  string `json:"cnoNumber"`
+}
+
+type ClockingEmployee struct {
+	ID    string `json:"id"`
+	ArpID string `json:"arpId"`
+	Cpf   string `json:"cpf"`
+	Pis   string `json:"pis"`
+}
+
+type ClockingSignature struct {
+	SignatureVersion int    `json:"signatureVersion"`
+	Signature        string `json:"signature"`
+}
+
+type ClockingInfo struct {
+	Company    ClockingCompany   `json:"company"`
+	Employee   ClockingEmployee  `json:"employee"`
+	AppVersion string            `json:"appVersion"`
+	TimeZone   string            `json:"timeZone"`
+	Signature  ClockingSignature `json:"signature"`
+	Use        string            `json:"use"`
+}
+
+// ClockingRequest é o corpo do registro de uma marcação.
+type ClockingRequest struct {
+	ClockingInfo ClockingInfo `json:"clockingInfo"`
+}
+
+// ClockingEvent é uma marcação retornada pela query de eventos da Senior.
+type ClockingEvent struct {
+	ID               string   `json:"id"`
+	DateEvent        string   `json:"dateEvent"`
+	TimeEvent        string   `json:"timeEvent"`
+	Cnpj             string   `json:"cnpj"`
+	Caepf            string   `json:"caepf"`
+	CnoNumber        string   `json:"cnoNumber"`
+	AppVersion       string   `json:"appVersion"`
+	TimeZone         string   `json:"timeZone"`
+	Signature        string   `json:"signature"`
+	SignatureVersion int      `json:"signatureVersion"`
+	Employee         Employee `json:"employee"`
+	Platform         string   `json:"platform"`
+	Use              int      `json:"use"`
+}
+
+type Employee struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Pis       string  `json:"pis"`
+	Shift     string  `json:"shift"`
+	Timetable string  `json:"timeTable"`
+	Company   Company `json:"company"`
+	ArpID     string  `json:"arpId"`
+	CpfNumber string  `json:"cpfNumber"`
+}
+
+type Company struct {
+	Cnpj  string `json:"cnpj"`
+	Name  string `json:"name"`
+	ID    string `json:"id"`
+	ArpID string `json:"arpId"`
+}
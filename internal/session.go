@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+// errNoCredentials indica que não há credenciais salvas para os subcomandos.
+var errNoCredentials = errors.New("nenhuma credencial salva, execute 'clockwerk login'")
+
+// errSSOTokenExpired indica que o token de um login por SSO expirou; sem
+// senha, só um novo token resolve.
+var errSSOTokenExpired = fmt.Errorf("%w: cole um novo com 'clockwerk login --token-stdin'", senior.ErrTokenExpired)
+
+// session concentra o acesso à Senior fora da TUI (subcomandos e daemon).
+// Quando attached, as chamadas passam pelo daemon e as credenciais locais não
+// são necessárias; caso contrário, renova o token antes que ele expire e
+// refaz o login uma única vez por sequência de falhas quando a Senior o
+// recusa, espelhando o hasAuthRecover da TUI. O ctx
+// cancela as requisições em andamento (Ctrl+C ou encerramento do daemon).
+type session struct {
+	ctx          context.Context
+	creds        core.UserCredentials
+	attached     bool
+	hasRecovered bool
+}
+
+// newSession prefere o daemon ativo; sem ele, exige credenciais salvas.
+func newSession(ctx context.Context) (*session, error) {
+	if core.DaemonRunning() {
+		return &session{ctx: ctx, attached: true}, nil
+	}
+	return newDirectSession(ctx)
+}
+
+// newDirectSession ignora o daemon e fala direto com a Senior (usada pelo
+// próprio daemon).
+func newDirectSession(ctx context.Context) (*session, error) {
+	creds, err := core.LoadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if creds.Token == "" {
+		return nil, errNoCredentials
+	}
+	return &session{ctx: ctx, creds: creds}, nil
+}
+
+// relogin refaz o login depois que a Senior recusou o token com cause. Se um
+// novo login já foi feito nesta sequência de falhas, devolve cause em vez de
+// tentar outra vez.
+func (s *session) relogin(cause error) error {
+	if s.creds.Password == "" {
+		return errSSOTokenExpired
+	}
+	if s.hasRecovered {
+		return fmt.Errorf("token recusado mesmo após novo login: %w", cause)
+	}
+	s.hasRecovered = true
+	return s.renew(core.RenewalExpired)
+}
+
+// ensureFreshToken renova o token antes que ele expire, para que sessões
+// longas (daemon) não esbarrem em um token vencido no meio de uma marcação.
+// Uma falha aqui não interrompe a chamada: o token atual ainda vale.
+func (s *session) ensureFreshToken() {
+	if s.attached || s.creds.Password == "" || !core.TokenNeedsRenewal(s.creds.Token, time.Now()) {
+		return
+	}
+	if err := s.renew(core.RenewalProactive); err != nil {
+		fmt.Fprintf(os.Stderr, "aviso: falha ao renovar o token: %v\n", err)
+	}
+}
+
+func (s *session) renew(reason string) error {
+	if err := core.RenewToken(s.ctx, &s.creds, reason); err != nil {
+		return err
+	}
+	if err := core.SaveCredentials(s.creds); err != nil {
+		fmt.Fprintf(os.Stderr, "aviso: %v\n", err)
+	}
+	return nil
+}
+
+func (s *session) events() (eventMsg, error) {
+	if s.attached {
+		return fetchEventMsg(s.ctx, "")
+	}
+
+	events, err := s.clockingEvents()
+	if err != nil {
+		return eventMsg{}, err
+	}
+	return newEventMsg(events)
+}
+
+// freshEvents é como events, mas não aceita a última busca do daemon: força
+// uma nova, para conferir uma marcação recém-enviada.
+func (s *session) freshEvents() (eventMsg, error) {
+	if s.attached {
+		return refreshEventMsg(s.ctx, "")
+	}
+	return s.events()
+}
+
+// eventsInRange completa msg com as marcações de from a to buscadas na
+// Senior, quando o período começa antes dos dados já conhecidos.
+func (s *session) eventsInRange(msg eventMsg, from, to string) (eventMsg, error) {
+	if earliest := earliestDateKey(msg.clocking); from == "" || (earliest != "" && from >= earliest) {
+		return msg, nil
+	}
+
+	var events []core.ClockingEvent
+	var err error
+	if s.attached {
+		var resp core.IPCResponse
+		resp, err = core.DaemonRange(from, to)
+		events = resp.Events
+	} else {
+		events, err = s.clockingEventsInRange(from, to)
+	}
+	if err != nil {
+		return msg, err
+	}
+
+	clocking, err := groupClockingEvents(events)
+	if err != nil {
+		return msg, err
+	}
+	return withClocking(msg, clocking), nil
+}
+
+// clockingEvents busca os eventos direto na Senior, refazendo o login quando
+// o token expirou.
+func (s *session) clockingEvents() ([]core.ClockingEvent, error) {
+	return s.withRelogin(func(token string) ([]core.ClockingEvent, error) {
+		return fetchClockingEvents(s.ctx, token)
+	})
+}
+
+// clockingEventsInRange pagina a busca na Senior até cobrir o período.
+func (s *session) clockingEventsInRange(from, to string) ([]core.ClockingEvent, error) {
+	return s.withRelogin(func(token string) ([]core.ClockingEvent, error) {
+		return fetchClockingEventsRange(s.ctx, token, from, to)
+	})
+}
+
+func (s *session) withRelogin(fetch func(token string) ([]core.ClockingEvent, error)) ([]core.ClockingEvent, error) {
+	s.ensureFreshToken()
+	events, err := fetch(s.creds.Token)
+	if err != nil && errors.Is(err, senior.ErrTokenExpired) {
+		if rerr := s.relogin(err); rerr != nil {
+			return nil, rerr
+		}
+		events, err = fetch(s.creds.Token)
+	}
+	if err == nil {
+		s.hasRecovered = false
+	}
+	return events, err
+}
+
+// punch registra a marcação. Um token recusado significa que a Senior não a
+// aceitou, então ela é reenviada uma única vez após refazer o login; qualquer
+// outra falha volta para quem chamou decidir.
+func (s *session) punch(msg eventMsg) (PostClockingMsg, error) {
+	if s.attached {
+		return postClockingEvent(s.ctx, "", msg)
+	}
+	s.ensureFreshToken()
+	punched, err := postClockingEventToAPI(s.ctx, s.creds.Token, msg)
+	if err != nil && errors.Is(err, senior.ErrTokenExpired) {
+		if rerr := s.relogin(err); rerr != nil {
+			return PostClockingMsg{}, rerr
+		}
+		punched, err = postClockingEventToAPI(s.ctx, s.creds.Token, msg)
+	}
+	if err == nil {
+		s.hasRecovered = false
+	}
+	return punched, err
+}
+
+// findPunch busca os eventos na Senior (ver freshEvents) até encontrar a
+// marcação feita a partir de before (ver findPunch), em até
+// punchVerifyAttempts tentativas. Sem resposta da Senior (punched vazio),
+// procura uma marcação nova desde since.
+func (s *session) findPunch(before eventMsg, punched PostClockingMsg, since time.Time) (clockingMsg, bool, error) {
+	known := knownClockingIDs(before)
+	for attempt := 0; ; attempt++ {
+		after, err := s.freshEvents()
+		if err != nil {
+			return clockingMsg{}, false, err
+		}
+		if c, ok := findPunch(after, punched, known, since); ok {
+			return c, true, nil
+		}
+		if attempt+1 >= punchVerifyAttempts {
+			return clockingMsg{}, false, nil
+		}
+		select {
+		case <-time.After(punchVerifyDelay):
+		case <-s.ctx.Done():
+			return clockingMsg{}, false, s.ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
+)
+
+func TestSessionReloginExhausted(t *testing.T) {
+	cause := fmt.Errorf("%w: token inválido", senior.ErrTokenExpired)
+
+	tests := []struct {
+		name  string
+		creds core.UserCredentials
+		want  error
+	}{
+		{name: "login por SSO", creds: core.UserCredentials{Token: "t"}, want: errSSOTokenExpired},
+		{name: "novo login já feito", creds: core.UserCredentials{Token: "t", Password: "senha"}, want: cause},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &session{ctx: context.Background(), creds: tt.creds, hasRecovered: true}
+			err := s.relogin(cause)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("relogin = %v, esperado %v", err, tt.want)
+			}
+			if errors.Is(err, errNoCredentials) {
+				t.Errorf("relogin = %v: há credenciais salvas", err)
+			}
+			if exitCodeFor(err) != exitNoAuth {
+				t.Errorf("código de saída = %d, esperado %d", exitCodeFor(err), exitNoAuth)
+			}
+		})
+	}
+}
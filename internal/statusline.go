@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+// statuslineStaleAfter é a idade a partir da qual o cache é sinalizado como
+// desatualizado. O dashboard atualiza a cada 10 min, então o dobro disso já
+// indica que nenhuma instância está renovando os dados.
+const statuslineStaleAfter = 20 * time.Minute
+
+// waybarStatus segue o formato "return-type": "json" dos módulos custom do
+// Waybar; class permite estilizar cada estado via CSS.
+type waybarStatus struct {
+	Text    string `json:"text"`
+	Tooltip string `json:"tooltip"`
+	Class   string `json:"class"`
+}
+
+const statuslineUsage = `Uso: clockwerk statusline [--format text|waybar]
+
+Imprime uma linha compacta com o timer, a situação (dentro/fora) e a saída
+prevista, lida apenas do cache local (nunca acessa a rede). O cache é
+atualizado pela TUI e pelos demais comandos a cada busca de eventos.
+
+Sempre termina com código 0 para não esconder o módulo da barra; o estado
+aparece no texto e, no formato waybar, no campo class (working, out, stale ou
+nodata).
+`
+
+func runStatusline(args []string) int {
+	fs := newFlagSet("statusline")
+	format := fs.String("format", "text", "formato: text (tmux, polybar, prompt) ou waybar")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, statuslineUsage) }
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if *format != "text" && *format != "waybar" {
+		fmt.Fprintf(os.Stderr, "formato inválido %q (use text ou waybar)\n", *format)
+		return exitUsage
+	}
+
+	status := buildStatusline(core.Now())
+
+	if *format == "waybar" {
+		json.NewEncoder(os.Stdout).Encode(status)
+		return exitOK
+	}
+
+	fmt.Fprintln(os.Stdout, status.Text)
+	return exitOK
+}
+
+// buildStatusline monta o estado compacto a partir do cache de eventos.
+func buildStatusline(now time.Time) waybarStatus {
+	cache, err := core.LoadEventCache()
+	if err != nil || cache.FetchedAt.IsZero() {
+		return waybarStatus{
+			Text:    "⏱ --:--",
+			Tooltip: "Clockwerk: sem dados em cache. Abra a TUI ou execute 'clockwerk status'.",
+			Class:   "nodata",
+		}
+	}
+
+	msg, err := newEventMsg(cache.Events)
+	if err != nil {
+		return waybarStatus{Text: "⏱ --:--", Tooltip: err.Error(), Class: "nodata"}
+	}
+
+	var m clockTimer
+	applyEventMsg(&m, msg)
+
+	icon, class := "○", "out"
+	if m.timerRunning {
+		icon, class = "●", "working"
+	}
+
+	parts := []string{fmt.Sprintf("%s %s", icon, formatClock(m.elapsed))}
+	predicted, hasPrediction := predictTodayExit(msg, now)
+	if hasPrediction {
+		parts = append(parts, "saída "+predicted.Format("15:04"))
+	}
+
+	age := time.Since(cache.FetchedAt)
+	if age > statuslineStaleAfter {
+		parts = append(parts, "⚠")
+		class = "stale"
+	}
+
+	var tooltip strings.Builder
+	tooltip.WriteString(msg.employeeName + "\n")
+	if m.timerRunning {
+		tooltip.WriteString("Situação: trabalhando\n")
+	} else {
+		tooltip.WriteString("Situação: fora\n")
+	}
+	tooltip.WriteString("Trabalhado: " + core.FormatDuration(m.elapsed) + "\n")
+	if hasPrediction {
+		tooltip.WriteString("Saída prevista: " + predicted.Format("15:04") + "\n")
+	}
+	var marks []string
+	for _, c := range msg.clocking[m.today] {
+		marks = append(marks, c.eventTime.Format("15:04"))
+	}
+	if len(marks) > 0 {
+		tooltip.WriteString("Marcações: " + strings.Join(marks, " ") + "\n")
+	}
+	tooltip.WriteString("Atualizado às " + cache.FetchedAt.Local().Format("15:04"))
+
+	return waybarStatus{
+		Text:    strings.Join(parts, " · "),
+		Tooltip: tooltip.String(),
+		Class:   class,
+	}
+}
+
+// formatClock formata uma duração como "HH:MM".
+func formatClock(d time.Duration) string {
+	totalMinutes := int(d / time.Minute)
+	return fmt.Sprintf("%02d:%02d", totalMinutes/60, totalMinutes%60)
+}
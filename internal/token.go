@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+// renewalOrigin identifica a operação que pediu a renovação proativa, para
+// que dispatchTokenRenewal retome só ela.
+type renewalOrigin int
+
+const (
+	renewForFetch renewalOrigin = iota // busca de eventos (fetchEventsCmd)
+	renewForPunch                      // marcação (startPunch)
+)
+
+// tokenRenewedMsg é o resultado de uma renovação proativa do token, feita
+// antes de uma busca de eventos ou de uma marcação.
+type tokenRenewedMsg struct {
+	token  string
+	err    error
+	origin renewalOrigin
+}
+
+func handleTokenRenewal(ctx context.Context, user string, password string, origin renewalOrigin) tea.Cmd {
+	return func() tea.Msg {
+		token, err := core.Login(ctx, user, password)
+		return tokenRenewedMsg{token: token, err: err, origin: origin}
+	}
+}
+
+// needsTokenRenewal informa se o token atual está perto de expirar e pode ser
+// renovado com a senha em memória.
+func needsTokenRenewal(m *clockTimer) bool {
+	return m.password != "" && core.TokenNeedsRenewal(m.token, time.Now())
+}
+
+// renewTokenCmd renova o token; dispatchTokenRenewal retoma a operação de
+// origin em seguida.
+func renewTokenCmd(m *clockTimer, origin renewalOrigin) tea.Cmd {
+	return handleTokenRenewal(m.ctx, fmt.Sprintf("%s@%s", m.cpf, m.domain), m.password, origin)
+}
+
+// fetchEventsCmd busca os eventos, renovando antes o token quando ele está
+// perto de expirar.
+func fetchEventsCmd(m *clockTimer) tea.Cmd {
+	if needsTokenRenewal(m) {
+		return renewTokenCmd(m, renewForFetch)
+	}
+	return handleGetClockingEvent(m.ctx, m.token)
+}
+
+// dispatchTokenRenewal conclui uma renovação proativa e retoma a operação que
+// a pediu: a marcação (ver startPunch) ou a busca de eventos. Uma falha só é
+// registrada: o token atual ainda vale e, se a Senior recusá-lo, o fluxo de
+// token expirado assume. ok é falso para as demais mensagens.
+func dispatchTokenRenewal(msg tea.Msg, m *clockTimer) (cmd tea.Cmd, ok bool) {
+	renewed, ok := msg.(tokenRenewedMsg)
+	if !ok {
+		return nil, false
+	}
+
+	if renewed.err != nil {
+		log.Printf("Erro ao renovar token: %v", renewed.err)
+	} else {
+		m.token = renewed.token
+		saveRenewedToken(m, core.RenewalProactive)
+	}
+
+	if renewed.origin == renewForPunch {
+		// A marcação só é enviada se ainda aguarda esta renovação; nunca é
+		// duplicada nem enviada depois de o usuário sair da tela.
+		if m.step == 6 && m.punch.state == punchRenewing {
+			return sendPunch(m), true
+		}
+		return nil, true
+	}
+	return handleGetClockingEvent(m.ctx, m.token), true
+}
+
+// describeTokenSession resume a validade do token e as renovações para a aba
+// Sobre, ex.: "expira 16/10 18:30 · renovada 3x (última 14:02)".
+func describeTokenSession(m *clockTimer) string {
+	var s string
+	if expiry, ok := core.TokenExpiry(m.token); ok {
+		s = "expira " + expiry.Format("02/01 15:04")
+	} else {
+		s = "validade desconhecida"
+	}
+
+	if n := len(m.tokenRenewals); n > 0 {
+		last := m.tokenRenewals[n-1]
+		s += fmt.Sprintf(" · renovada %dx (última %s)", n, last.At.Format("02/01 15:04"))
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDispatchTokenRenewal(t *testing.T) {
+	tests := []struct {
+		name      string
+		origin    renewalOrigin
+		step      int
+		state     punchState
+		err       error
+		wantCmd   bool
+		wantState punchState
+	}{
+		{name: "busca no dashboard", origin: renewForFetch, step: 5, wantCmd: true},
+		{name: "busca que termina durante a marcação", origin: renewForFetch, step: 6, state: punchRenewing, wantCmd: true, wantState: punchRenewing},
+		{name: "marcação aguardando a renovação", origin: renewForPunch, step: 6, state: punchRenewing, wantCmd: true, wantState: punchSending},
+		{name: "marcação com falha na renovação", origin: renewForPunch, step: 6, state: punchRenewing, err: errors.New("rede"), wantCmd: true, wantState: punchSending},
+		{name: "marcação já enviada", origin: renewForPunch, step: 6, state: punchChecking, wantState: punchChecking},
+		{name: "marcação abandonada", origin: renewForPunch, step: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &clockTimer{ctx: context.Background(), step: tt.step, token: "antigo"}
+			m.punch.state = tt.state
+
+			cmd, ok := dispatchTokenRenewal(tokenRenewedMsg{token: "novo", err: tt.err, origin: tt.origin}, m)
+			if !ok {
+				t.Fatal("mensagem não tratada")
+			}
+			if (cmd != nil) != tt.wantCmd {
+				t.Errorf("cmd = %v, esperado cmd: %v", cmd != nil, tt.wantCmd)
+			}
+			if tt.step == 6 && m.punch.state != tt.wantState {
+				t.Errorf("estado = %v, esperado %v", m.punch.state, tt.wantState)
+			}
+
+			wantToken := "novo"
+			if tt.err != nil {
+				wantToken = "antigo"
+			}
+			if m.token != wantToken {
+				t.Errorf("token = %q, esperado %q", m.token, wantToken)
+			}
+		})
+	}
+}
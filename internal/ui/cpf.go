@@ -2,13 +2,15 @@ package ui
 
 import (
 	"fmt"
-	"regexp"
 
 	"github.com/charmbracelet/huh"
 	"github.com/diegodario88/clockwerk/internal/core"
+	"github.com/diegodario88/clockwerk/internal/senior"
 )
 
-func NewCPFForm(initialDomainValue string, initialCpfValue string) *huh.Form {
+// NewCPFForm pede domínio e CPF e a forma de autenticação: senha ou um token
+// obtido via SSO (campo "sso").
+func NewCPFForm(initialDomainValue string, initialCpfValue string, initialSSO bool) *huh.Form {
 	validateCPF := func(s string) error {
 		if len(s) != 11 {
 			return fmt.Errorf("CPF deve conter 11 dígitos")
@@ -22,20 +24,13 @@ func NewCPFForm(initialDomainValue string, initialCpfValue string) *huh.Form {
 	}
 
 	validateDomain := func(s string) error {
-		if s == "" {
-			return fmt.Errorf("o domínio não pode estar vazio")
-		}
-		re := regexp.MustCompile(`^([a-zA-Z0-9]+(-[a-zA-Z0-9]+)*\.)+[a-zA-Z]{2,}$`)
-		if !re.MatchString(s) {
-			return fmt.Errorf("o domínio não é válido")
-		}
-		return nil
+		return senior.ValidateTenant(senior.TenantFromDomain(s))
 	}
 
 	domainInput := huh.NewInput().
 		Key("domain").
 		Title("Domínio").
-		Description("Insira o domínio da sua empresa").
+		Description("Insira o domínio da sua empresa na Senior X (o que vem após o @ no login)").
 		Placeholder("exemplo.com.br").
 		Value(&initialDomainValue).
 		Validate(validateDomain)
@@ -49,6 +44,16 @@ func NewCPFForm(initialDomainValue string, initialCpfValue string) *huh.Form {
 		CharLimit(11).
 		Validate(validateCPF)
 
+	ssoSelect := huh.NewSelect[bool]().
+		Key("sso").
+		Title("Autenticação").
+		Options(
+			huh.NewOption("Senha", false),
+			huh.NewOption("Token SSO (colado do navegador)", true),
+		).
+		Value(&initialSSO).
+		Inline(true)
+
 	nextConfirm0 := huh.NewConfirm().
 		Value(&core.DefaultConfirm).
 		Key("next").
@@ -56,7 +61,7 @@ func NewCPFForm(initialDomainValue string, initialCpfValue string) *huh.Form {
 		Negative("")
 
 	return huh.NewForm(
-		huh.NewGroup(domainInput, cpfInput, nextConfirm0),
+		huh.NewGroup(domainInput, cpfInput, ssoSelect, nextConfirm0),
 	).
 		WithWidth(core.AppWidth).
 		WithShowHelp(true).
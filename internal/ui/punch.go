@@ -1,27 +1,68 @@
 package ui
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/diegodario88/clockwerk/internal/core"
 )
 
-func NewPunchConfirmForm() *huh.Form {
+// PunchSummary descreve a marcação a confirmar: o que ela significa
+// (core.PunchIntent), suas consequências e os motivos que a bloqueiam (ver
+// core.CheckPunch).
+type PunchSummary struct {
+	Intent string
+	At     time.Time
+	// Details são as consequências, ex.: "Trabalhado até agora: 3h58m".
+	Details      []string
+	BlockReasons []string
+}
+
+// NewPunchConfirmForm pede a confirmação da marcação descrita em summary.
+// Com BlockReasons, mostra os motivos e só segue com "Bater mesmo assim", que
+// deixa de ser a opção padrão.
+func NewPunchConfirmForm(summary PunchSummary) *huh.Form {
 	defaultValue := true
+	title := fmt.Sprintf("Registrar %s às %s?", summary.Intent, summary.At.Format("15:04"))
+	affirmative := "Sim"
+
+	var description strings.Builder
+	if len(summary.Details) > 0 {
+		description.WriteString(
+			lipgloss.NewStyle().
+				Italic(true).
+				Render(strings.Join(summary.Details, "\n")),
+		)
+	}
+
+	if len(summary.BlockReasons) > 0 {
+		defaultValue = false
+		title = fmt.Sprintf("%s às %s bloqueada", summary.Intent, summary.At.Format("15:04"))
+		affirmative = "Bater mesmo assim"
+		if description.Len() > 0 {
+			description.WriteString("\n")
+		}
+		description.WriteString(
+			lipgloss.NewStyle().
+				Italic(true).
+				Foreground(lipgloss.Color(core.SunflowerYellow)).
+				Render("⚠ " + strings.Join(summary.BlockReasons, "\n⚠ ")),
+		)
+	}
+
 	confirm := huh.NewConfirm().
 		Key("confirm").
 		Title(
 			lipgloss.NewStyle().
 				Bold(true).
-				Render("Deseja bater o ponto?"),
-		).
-		Description(
-			lipgloss.NewStyle().
-				Italic(true).
-				Render("Ao confirmar, enviaremos uma http request para senior"),
+				Render(title),
 		).
+		Description(description.String()).
 		Value(&defaultValue).
-		Affirmative("Sim").
+		Affirmative(affirmative).
 		Negative("Cancelar")
 
 	return huh.NewForm(
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+	"github.com/diegodario88/clockwerk/internal/core"
+)
+
+func NewTokenForm() *huh.Form {
+	var token string
+	tokenInput := huh.NewInput().
+		Key("token").
+		Title("Token").
+		Description("Cole o token de acesso da Senior X obtido no navegador (SSO)").
+		Placeholder("eyJhbGciOi...").
+		Value(&token).
+		Validate(func(s string) error {
+			if core.NormalizeBearerToken(s) == "" {
+				return fmt.Errorf("o token não pode estar vazio")
+			}
+			return nil
+		}).
+		EchoMode(huh.EchoModePassword)
+
+	nextConfirm := huh.NewConfirm().
+		Key("next").
+		Value(&core.DefaultConfirm).
+		Negative("Voltar").
+		Affirmative("Prosseguir")
+
+	return huh.NewForm(
+		huh.NewGroup(tokenInput, nextConfirm),
+	).
+		WithWidth(core.AppWidth).
+		WithShowHelp(true).
+		WithShowErrors(true).
+		WithTheme(core.Theme)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"io"
@@ -17,12 +18,14 @@ func main() {
 		hasDebug = true
 	}
 
+	var logFile *os.File
 	if hasDebug {
 		f, err := tea.LogToFile("debug.log", "debug")
 		if err != nil {
 			fmt.Println("fatal:", err)
 			os.Exit(1)
 		}
+		logFile = f
 		defer f.Close()
 	} else {
 		// Sem modo debug, descarta o log padrão: a TUI usa altscreen e qualquer
@@ -31,10 +34,25 @@ func main() {
 		log.SetOutput(io.Discard)
 	}
 
-	clockTimer := internal.NewClockTimer()
+	// Com argumentos, executa um subcomando não interativo (status, punch...)
+	// e encerra com o código de saída correspondente.
+	if len(os.Args) > 1 {
+		code := internal.RunCommand(os.Args[1:])
+		if logFile != nil {
+			logFile.Close()
+		}
+		os.Exit(code)
+	}
+
+	// Cancelado ao sair da TUI, interrompe as requisições ainda em andamento.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	clockTimer := internal.NewClockTimer(ctx)
 	program := tea.NewProgram(clockTimer, tea.WithAltScreen())
 
-	if _, err := program.Run(); err != nil {
+	_, err := program.Run()
+	cancel()
+	if err != nil {
 		if hasDebug {
 			log.Printf("Erro ao executar o programa: %s\n", err)
 		}